@@ -0,0 +1,48 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import "errors"
+
+var (
+	// ErrExternalClusterNotFound is raised when the requested external
+	// cluster is not defined in the Cluster spec.
+	ErrExternalClusterNotFound = errors.New("external cluster not found")
+
+	// ErrPluginNotUsedByExternalCluster is raised when the requested
+	// external cluster has no configuration for the plugin.
+	ErrPluginNotUsedByExternalCluster = errors.New("external cluster is not using the plugin")
+
+	// ErrNoRecoverySource is raised when the Cluster is not being
+	// bootstrapped from an external cluster via the recovery method.
+	ErrNoRecoverySource = errors.New("cluster has no recovery source")
+
+	// ErrUnsupportedTemplateConstruct is raised when a templated parameter
+	// uses anything other than the fields of TemplateData.
+	ErrUnsupportedTemplateConstruct = errors.New("unsupported template construct")
+
+	// ErrRenderedParameterTooLong is raised when a templated parameter
+	// renders to more than MaxRenderedParameterLength bytes.
+	ErrRenderedParameterTooLong = errors.New("rendered parameter too long")
+
+	// ErrUnknownPostgresMajorVersion is raised when the PostgreSQL major
+	// version of a Cluster cannot be detected.
+	ErrUnknownPostgresMajorVersion = errors.New("cannot detect the PostgreSQL major version")
+)
@@ -23,17 +23,50 @@ import (
 	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
 )
 
+// ConfigurationSource is the place where the configuration of a plugin
+// has been read from.
+type ConfigurationSource string
+
+const (
+	// SourceCluster is used when the plugin configuration comes from
+	// the `.spec.plugins` stanza of the Cluster.
+	SourceCluster ConfigurationSource = "cluster"
+
+	// SourceBackup is used when the plugin configuration comes from
+	// the `.spec.pluginConfiguration` stanza of a Backup.
+	SourceBackup ConfigurationSource = "backup"
+
+	// SourceScheduledBackup is used when the plugin configuration comes from
+	// the `.spec.pluginConfiguration` stanza of a ScheduledBackup.
+	SourceScheduledBackup ConfigurationSource = "scheduledBackup"
+
+	// SourceExternalCluster is used when the plugin configuration comes from
+	// the `.plugin` stanza of an entry of `.spec.externalClusters`.
+	SourceExternalCluster ConfigurationSource = "externalCluster"
+)
+
 // Plugin represents a plugin with its associated cluster and parameters.
 type Plugin struct {
 	Cluster *apiv1.Cluster
 	// Parameters are the configuration parameters of this plugin
 	Parameters  map[string]string
 	PluginIndex int
+
+	// Source is where the configuration of this plugin has been read from
+	Source ConfigurationSource
+	// ExternalClusterIndex is the index of the external cluster whose plugin
+	// configuration has been used, or -1 when no external cluster was involved
+	ExternalClusterIndex int
 }
 
 // NewPlugin creates a new Plugin instance for the given cluster and plugin name.
+// If the plugin is configured more than once, the last entry is used.
 func NewPlugin(cluster apiv1.Cluster, pluginName string) *Plugin {
-	result := &Plugin{Cluster: &cluster}
+	result := &Plugin{
+		Cluster:              &cluster,
+		Source:               SourceCluster,
+		ExternalClusterIndex: -1,
+	}
 
 	result.PluginIndex = -1
 	for idx, cfg := range result.Cluster.Spec.Plugins {
@@ -45,3 +78,25 @@ func NewPlugin(cluster apiv1.Cluster, pluginName string) *Plugin {
 
 	return result
 }
+
+// NewPlugins creates a Plugin instance for every entry of the given cluster
+// referring to the passed plugin name. This is meant to be used by plugins
+// supporting multiple instances in the same Cluster.
+func NewPlugins(cluster apiv1.Cluster, pluginName string) []*Plugin {
+	var result []*Plugin
+	for idx, cfg := range cluster.Spec.Plugins {
+		if cfg.Name != pluginName {
+			continue
+		}
+
+		result = append(result, &Plugin{
+			Cluster:              &cluster,
+			Parameters:           cfg.Parameters,
+			PluginIndex:          idx,
+			Source:               SourceCluster,
+			ExternalClusterIndex: -1,
+		})
+	}
+
+	return result
+}
@@ -86,3 +86,45 @@ var _ = Describe("NewPlugin", func() {
 		})
 	})
 })
+
+var _ = Describe("NewPlugins", func() {
+	cluster := apiv1.Cluster{
+		Spec: apiv1.ClusterSpec{
+			Plugins: []apiv1.PluginConfiguration{
+				{
+					Name: "plugin1",
+					Parameters: map[string]string{
+						"param": "first",
+					},
+				},
+				{
+					Name: "plugin2",
+				},
+				{
+					Name: "plugin1",
+					Parameters: map[string]string{
+						"param": "second",
+					},
+				},
+			},
+		},
+	}
+
+	It("should return every entry of the plugin", func() {
+		plugins := NewPlugins(cluster, "plugin1")
+		Expect(plugins).To(HaveLen(2))
+		Expect(plugins[0].PluginIndex).To(Equal(0))
+		Expect(plugins[0].Parameters).To(HaveKeyWithValue("param", "first"))
+		Expect(plugins[1].PluginIndex).To(Equal(2))
+		Expect(plugins[1].Parameters).To(HaveKeyWithValue("param", "second"))
+	})
+
+	It("should keep the last entry when using NewPlugin", func() {
+		plugin := NewPlugin(cluster, "plugin1")
+		Expect(plugin.PluginIndex).To(Equal(2))
+	})
+
+	It("should return nothing when the plugin is not configured", func() {
+		Expect(NewPlugins(cluster, "plugin3")).To(BeEmpty())
+	})
+})
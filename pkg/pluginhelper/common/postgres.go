@@ -0,0 +1,65 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"fmt"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	"github.com/cloudnative-pg/machinery/pkg/image/reference"
+	"github.com/cloudnative-pg/machinery/pkg/postgres/version"
+)
+
+// GetPostgresMajorVersion gets the PostgreSQL major version the Cluster
+// is expected to run. The version is detected, in order, from:
+//
+//   - the `.spec.imageCatalogRef.major` field
+//   - the tag of the `.spec.imageName` image
+//   - the `.status.pgDataImageInfo.majorVersion` field
+//   - the tag of the `.status.image` image
+func GetPostgresMajorVersion(cluster *apiv1.Cluster) (int, error) {
+	if cluster.Spec.ImageCatalogRef != nil && cluster.Spec.ImageCatalogRef.Major > 0 {
+		return cluster.Spec.ImageCatalogRef.Major, nil
+	}
+
+	if cluster.Spec.ImageName != "" {
+		return getMajorVersionFromImage(cluster.Spec.ImageName)
+	}
+
+	if cluster.Status.PGDataImageInfo != nil && cluster.Status.PGDataImageInfo.MajorVersion > 0 {
+		return cluster.Status.PGDataImageInfo.MajorVersion, nil
+	}
+
+	if cluster.Status.Image != "" {
+		return getMajorVersionFromImage(cluster.Status.Image)
+	}
+
+	return 0, ErrUnknownPostgresMajorVersion
+}
+
+func getMajorVersionFromImage(imageName string) (int, error) {
+	tag := reference.New(imageName).Tag
+	data, err := version.FromTag(tag)
+	if err != nil {
+		return 0, fmt.Errorf("%w: image %q: %w", ErrUnknownPostgresMajorVersion, imageName, err)
+	}
+
+	return int(data.Major()), nil //nolint:gosec
+}
@@ -0,0 +1,132 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"fmt"
+	"maps"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+)
+
+// NewPluginFromBackup creates a new Plugin instance for the given cluster,
+// backup and plugin name.
+//
+// The parameters defined in the `.spec.pluginConfiguration` stanza of the
+// Backup take precedence over the ones defined for the same plugin in the
+// `.spec.plugins` stanza of the Cluster.
+func NewPluginFromBackup(cluster apiv1.Cluster, backup apiv1.Backup, pluginName string) *Plugin {
+	result := NewPlugin(cluster, pluginName)
+
+	cfg := backup.Spec.PluginConfiguration
+	if cfg != nil && cfg.Name == pluginName {
+		result.Source = SourceBackup
+		result.Parameters = mergeParameters(result.Parameters, cfg.Parameters)
+	}
+
+	return result
+}
+
+// NewPluginFromScheduledBackup creates a new Plugin instance for the given
+// cluster, scheduled backup and plugin name.
+//
+// The parameters defined in the `.spec.pluginConfiguration` stanza of the
+// ScheduledBackup take precedence over the ones defined for the same plugin
+// in the `.spec.plugins` stanza of the Cluster.
+func NewPluginFromScheduledBackup(
+	cluster apiv1.Cluster,
+	scheduledBackup apiv1.ScheduledBackup,
+	pluginName string,
+) *Plugin {
+	result := NewPlugin(cluster, pluginName)
+
+	cfg := scheduledBackup.Spec.PluginConfiguration
+	if cfg != nil && cfg.Name == pluginName {
+		result.Source = SourceScheduledBackup
+		result.Parameters = mergeParameters(result.Parameters, cfg.Parameters)
+	}
+
+	return result
+}
+
+// NewPluginFromExternalCluster creates a new Plugin instance for the given
+// cluster, external cluster and plugin name.
+//
+// Only the parameters defined in the `.plugin` stanza of the external
+// cluster are used: none of the ones defined for the same plugin in the
+// `.spec.plugins` stanza of the Cluster are inherited, as they refer to
+// the Cluster itself rather than to the external one.
+// ErrPluginNotUsedByExternalCluster is returned if the external cluster
+// has no configuration for the passed plugin.
+func NewPluginFromExternalCluster(
+	cluster apiv1.Cluster,
+	externalClusterName string,
+	pluginName string,
+) (*Plugin, error) {
+	result := NewPlugin(cluster, pluginName)
+
+	for idx, externalCluster := range result.Cluster.Spec.ExternalClusters {
+		if externalCluster.Name != externalClusterName {
+			continue
+		}
+
+		cfg := externalCluster.PluginConfiguration
+		if cfg == nil || cfg.Name != pluginName {
+			return nil, fmt.Errorf("%w: %s (%s)", ErrPluginNotUsedByExternalCluster, externalClusterName, pluginName)
+		}
+
+		result.ExternalClusterIndex = idx
+		result.Source = SourceExternalCluster
+		result.Parameters = maps.Clone(cfg.Parameters)
+
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrExternalClusterNotFound, externalClusterName)
+}
+
+// NewPluginFromRecoverySource creates a new Plugin instance using the
+// external cluster referenced by the `.spec.bootstrap.recovery.source`
+// field of the Cluster.
+//
+// The same rules of NewPluginFromExternalCluster apply.
+func NewPluginFromRecoverySource(cluster apiv1.Cluster, pluginName string) (*Plugin, error) {
+	if cluster.Spec.Bootstrap == nil ||
+		cluster.Spec.Bootstrap.Recovery == nil ||
+		cluster.Spec.Bootstrap.Recovery.Source == "" {
+		return nil, ErrNoRecoverySource
+	}
+
+	return NewPluginFromExternalCluster(cluster, cluster.Spec.Bootstrap.Recovery.Source, pluginName)
+}
+
+// mergeParameters creates a new map containing the base parameters
+// overridden by the passed ones.
+func mergeParameters(base, overrides map[string]string) map[string]string {
+	if base == nil && overrides == nil {
+		return nil
+	}
+
+	result := make(map[string]string, len(base)+len(overrides))
+	maps.Copy(result, base)
+	maps.Copy(result, overrides)
+
+	return result
+}
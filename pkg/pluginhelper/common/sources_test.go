@@ -0,0 +1,183 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Plugin configuration sources", func() {
+	var cluster apiv1.Cluster
+
+	BeforeEach(func() {
+		cluster = apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				Plugins: []apiv1.PluginConfiguration{
+					{
+						Name: "test-plugin",
+						Parameters: map[string]string{
+							"param1": "cluster",
+							"param2": "cluster",
+						},
+					},
+				},
+				ExternalClusters: []apiv1.ExternalCluster{
+					{
+						Name: "other",
+					},
+					{
+						Name: "origin",
+						PluginConfiguration: &apiv1.PluginConfiguration{
+							Name: "test-plugin",
+							Parameters: map[string]string{
+								"param2": "origin",
+							},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	Context("NewPluginFromBackup", func() {
+		It("should let the backup parameters take precedence", func() {
+			backup := apiv1.Backup{
+				Spec: apiv1.BackupSpec{
+					PluginConfiguration: &apiv1.BackupPluginConfiguration{
+						Name: "test-plugin",
+						Parameters: map[string]string{
+							"param1": "backup",
+						},
+					},
+				},
+			}
+
+			plugin := NewPluginFromBackup(cluster, backup, "test-plugin")
+			Expect(plugin.Source).To(Equal(SourceBackup))
+			Expect(plugin.PluginIndex).To(Equal(0))
+			Expect(plugin.Parameters).To(Equal(map[string]string{
+				"param1": "backup",
+				"param2": "cluster",
+			}))
+			Expect(cluster.Spec.Plugins[0].Parameters).To(HaveKeyWithValue("param1", "cluster"))
+		})
+
+		It("should ignore the backup configuration of a different plugin", func() {
+			backup := apiv1.Backup{
+				Spec: apiv1.BackupSpec{
+					PluginConfiguration: &apiv1.BackupPluginConfiguration{
+						Name: "another-plugin",
+						Parameters: map[string]string{
+							"param1": "backup",
+						},
+					},
+				},
+			}
+
+			plugin := NewPluginFromBackup(cluster, backup, "test-plugin")
+			Expect(plugin.Source).To(Equal(SourceCluster))
+			Expect(plugin.Parameters).To(HaveKeyWithValue("param1", "cluster"))
+		})
+	})
+
+	Context("NewPluginFromScheduledBackup", func() {
+		It("should let the scheduled backup parameters take precedence", func() {
+			scheduledBackup := apiv1.ScheduledBackup{
+				Spec: apiv1.ScheduledBackupSpec{
+					PluginConfiguration: &apiv1.BackupPluginConfiguration{
+						Name: "test-plugin",
+						Parameters: map[string]string{
+							"param3": "scheduled",
+						},
+					},
+				},
+			}
+
+			plugin := NewPluginFromScheduledBackup(cluster, scheduledBackup, "test-plugin")
+			Expect(plugin.Source).To(Equal(SourceScheduledBackup))
+			Expect(plugin.Parameters).To(Equal(map[string]string{
+				"param1": "cluster",
+				"param2": "cluster",
+				"param3": "scheduled",
+			}))
+		})
+	})
+
+	Context("NewPluginFromExternalCluster", func() {
+		It("should use only the external cluster parameters", func() {
+			plugin, err := NewPluginFromExternalCluster(cluster, "origin", "test-plugin")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plugin.Source).To(Equal(SourceExternalCluster))
+			Expect(plugin.ExternalClusterIndex).To(Equal(1))
+			Expect(plugin.Parameters).To(Equal(map[string]string{
+				"param2": "origin",
+			}))
+		})
+
+		It("should fail when the external cluster is not using the plugin", func() {
+			_, err := NewPluginFromExternalCluster(cluster, "other", "test-plugin")
+			Expect(err).To(MatchError(ErrPluginNotUsedByExternalCluster))
+		})
+
+		It("should fail when the external cluster is using another plugin", func() {
+			_, err := NewPluginFromExternalCluster(cluster, "origin", "another-plugin")
+			Expect(err).To(MatchError(ErrPluginNotUsedByExternalCluster))
+		})
+
+		It("should fail when the external cluster does not exist", func() {
+			_, err := NewPluginFromExternalCluster(cluster, "missing", "test-plugin")
+			Expect(err).To(MatchError(ErrExternalClusterNotFound))
+		})
+	})
+
+	Context("NewPluginFromRecoverySource", func() {
+		It("should use the external cluster referenced by the recovery source", func() {
+			cluster.Spec.Bootstrap = &apiv1.BootstrapConfiguration{
+				Recovery: &apiv1.BootstrapRecovery{
+					Source: "origin",
+				},
+			}
+
+			plugin, err := NewPluginFromRecoverySource(cluster, "test-plugin")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plugin.Source).To(Equal(SourceExternalCluster))
+			Expect(plugin.Parameters).To(HaveKeyWithValue("param2", "origin"))
+		})
+
+		It("should fail when the recovery source is not using the plugin", func() {
+			cluster.Spec.Bootstrap = &apiv1.BootstrapConfiguration{
+				Recovery: &apiv1.BootstrapRecovery{
+					Source: "other",
+				},
+			}
+
+			_, err := NewPluginFromRecoverySource(cluster, "test-plugin")
+			Expect(err).To(MatchError(ErrPluginNotUsedByExternalCluster))
+		})
+
+		It("should fail when the cluster is not bootstrapped via recovery", func() {
+			_, err := NewPluginFromRecoverySource(cluster, "test-plugin")
+			Expect(err).To(MatchError(ErrNoRecoverySource))
+		})
+	})
+})
@@ -0,0 +1,235 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+)
+
+const templateActionDelimiter = "{{"
+
+// MaxRenderedParameterLength is the maximum length, in bytes,
+// of a rendered parameter.
+const MaxRenderedParameterLength = 4096
+
+// TemplateData is the set of Cluster fields that can be used inside
+// the templated parameters of a plugin, i.e.
+//
+//	path=/backups/{{ .Namespace }}/{{ .Name }}
+//
+// Only these fields are exposed to the templates, to avoid leaking
+// unrelated parts of the Cluster definition. As parameters are written
+// by users, templates are restricted to actions printing these fields,
+// i.e. `{{ .Labels.team }}`: control structures, variables, literals
+// and function calls are refused with ErrUnsupportedTemplateConstruct.
+type TemplateData struct {
+	// Name is the name of the Cluster
+	Name string
+	// Namespace is the namespace of the Cluster
+	Namespace string
+	// UID is the UID of the Cluster
+	UID string
+	// Labels are the labels of the Cluster
+	Labels map[string]string
+
+	postgresMajorVersion    int
+	postgresMajorVersionErr error
+}
+
+// PostgresMajorVersion is the PostgreSQL major version of the Cluster.
+// Rendering a template using it will fail when the version cannot
+// be detected.
+func (data TemplateData) PostgresMajorVersion() (int, error) {
+	return data.postgresMajorVersion, data.postgresMajorVersionErr
+}
+
+// NewTemplateData extracts the template data from a Cluster.
+func NewTemplateData(cluster *apiv1.Cluster) TemplateData {
+	result := TemplateData{
+		Name:      cluster.Name,
+		Namespace: cluster.Namespace,
+		UID:       string(cluster.UID),
+		Labels:    maps.Clone(cluster.Labels),
+	}
+	result.postgresMajorVersion, result.postgresMajorVersionErr = GetPostgresMajorVersion(cluster)
+
+	return result
+}
+
+// TemplateError is raised when a plugin parameter cannot be parsed or
+// rendered as a template.
+type TemplateError struct {
+	// Parameter is the name of the offending parameter
+	Parameter string
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements the error interface.
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("while rendering parameter %q: %v", e.Parameter, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// IsTemplated returns true if the value of the passed parameter
+// contains a template action.
+func (p *Plugin) IsTemplated(name string) bool {
+	return strings.Contains(p.Parameters[name], templateActionDelimiter)
+}
+
+// RenderParameter renders the value of the passed parameter using
+// the Cluster fields exposed by TemplateData. Parameters not containing
+// a template action are returned unchanged.
+func (p *Plugin) RenderParameter(name string) (string, error) {
+	value := p.Parameters[name]
+	if !p.IsTemplated(name) {
+		return value, nil
+	}
+
+	return renderTemplate(name, value, NewTemplateData(p.Cluster))
+}
+
+// RenderParameters renders every parameter of the plugin, returning
+// a new map. The first error encountered is returned.
+func (p *Plugin) RenderParameters() (map[string]string, error) {
+	data := NewTemplateData(p.Cluster)
+	result := make(map[string]string, len(p.Parameters))
+	for name, value := range p.Parameters {
+		if !p.IsTemplated(name) {
+			result[name] = value
+			continue
+		}
+
+		rendered, err := renderTemplate(name, value, data)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = rendered
+	}
+
+	return result, nil
+}
+
+// ValidateParameterTemplates parses and renders every templated
+// parameter, returning the errors indexed by parameter name.
+func (p *Plugin) ValidateParameterTemplates() map[string]error {
+	data := NewTemplateData(p.Cluster)
+	result := make(map[string]error)
+	for name, value := range p.Parameters {
+		if !p.IsTemplated(name) {
+			continue
+		}
+
+		if _, err := renderTemplate(name, value, data); err != nil {
+			result[name] = err
+		}
+	}
+
+	return result
+}
+
+func renderTemplate(name, value string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", &TemplateError{Parameter: name, Err: err}
+	}
+
+	if err := checkTemplate(tmpl); err != nil {
+		return "", &TemplateError{Parameter: name, Err: err}
+	}
+
+	result := &limitedBuilder{limit: MaxRenderedParameterLength}
+	if err := tmpl.Execute(result, data); err != nil {
+		return "", &TemplateError{Parameter: name, Err: err}
+	}
+
+	return result.String(), nil
+}
+
+// checkTemplate checks that a template only contains text and
+// actions printing a field of TemplateData.
+func checkTemplate(tmpl *template.Template) error {
+	if len(tmpl.Templates()) > 1 {
+		return fmt.Errorf("%w: template definitions are not allowed", ErrUnsupportedTemplateConstruct)
+	}
+
+	if tmpl.Tree == nil || tmpl.Root == nil {
+		return nil
+	}
+
+	for _, node := range tmpl.Root.Nodes {
+		switch node := node.(type) {
+		case *parse.TextNode, *parse.CommentNode:
+			continue
+
+		case *parse.ActionNode:
+			if !isFieldAccess(node.Pipe) {
+				return fmt.Errorf("%w: %s, only fields can be used", ErrUnsupportedTemplateConstruct, node)
+			}
+
+		default:
+			return fmt.Errorf("%w: %s", ErrUnsupportedTemplateConstruct, node)
+		}
+	}
+
+	return nil
+}
+
+// isFieldAccess checks if a pipeline is a single field access,
+// i.e. `.Name`, without variables or arguments.
+func isFieldAccess(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 {
+		return false
+	}
+
+	args := pipe.Cmds[0].Args
+	if len(args) != 1 {
+		return false
+	}
+
+	_, ok := args[0].(*parse.FieldNode)
+	return ok
+}
+
+// limitedBuilder is a strings.Builder failing when
+// more than limit bytes are written.
+type limitedBuilder struct {
+	strings.Builder
+	limit int
+}
+
+// Write implements the io.Writer interface.
+func (b *limitedBuilder) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("%w: more than %d bytes", ErrRenderedParameterTooLong, b.limit)
+	}
+
+	return b.Builder.Write(p)
+}
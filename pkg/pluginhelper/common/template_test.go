@@ -0,0 +1,193 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"strings"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parameter templates", func() {
+	var cluster apiv1.Cluster
+
+	BeforeEach(func() {
+		cluster = apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cluster-example",
+				Namespace: "default",
+				UID:       "1234",
+				Labels: map[string]string{
+					"team": "dba",
+				},
+			},
+			Spec: apiv1.ClusterSpec{
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:17.2",
+				Plugins: []apiv1.PluginConfiguration{
+					{
+						Name: "test-plugin",
+						Parameters: map[string]string{
+							"path":    "/backups/{{ .Namespace }}/{{ .Name }}/{{ .UID }}",
+							"version": "pg{{ .PostgresMajorVersion }}-{{ .Labels.team }}",
+							"plain":   "value",
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("should render the templated parameters", func() {
+		plugin := NewPlugin(cluster, "test-plugin")
+
+		path, err := plugin.RenderParameter("path")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(path).To(Equal("/backups/default/cluster-example/1234"))
+
+		parameters, err := plugin.RenderParameters()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parameters).To(Equal(map[string]string{
+			"path":    "/backups/default/cluster-example/1234",
+			"version": "pg17-dba",
+			"plain":   "value",
+		}))
+		Expect(plugin.ValidateParameterTemplates()).To(BeEmpty())
+	})
+
+	It("should report parameters referencing unknown fields", func() {
+		cluster.Spec.Plugins[0].Parameters["path"] = "{{ .Spec.Instances }}"
+		plugin := NewPlugin(cluster, "test-plugin")
+
+		_, err := plugin.RenderParameter("path")
+		Expect(err).To(HaveOccurred())
+
+		var templateErr *TemplateError
+		Expect(err).To(BeAssignableToTypeOf(templateErr))
+
+		errs := plugin.ValidateParameterTemplates()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs).To(HaveKey("path"))
+	})
+
+	It("should report missing labels and malformed templates", func() {
+		cluster.Spec.Plugins[0].Parameters["path"] = "{{ .Labels.missing }}"
+		cluster.Spec.Plugins[0].Parameters["plain"] = "{{ .Name "
+		plugin := NewPlugin(cluster, "test-plugin")
+
+		errs := plugin.ValidateParameterTemplates()
+		Expect(errs).To(HaveLen(2))
+		Expect(errs).To(HaveKey("path"))
+		Expect(errs).To(HaveKey("plain"))
+	})
+
+	DescribeTable("should refuse unsupported template constructs",
+		func(value string) {
+			cluster.Spec.Plugins[0].Parameters["path"] = value
+			plugin := NewPlugin(cluster, "test-plugin")
+
+			_, err := plugin.RenderParameter("path")
+			Expect(err).To(MatchError(ErrUnsupportedTemplateConstruct))
+			Expect(plugin.ValidateParameterTemplates()).To(HaveKey("path"))
+		},
+		Entry("range", "{{ range 1000000000 }}{{ range 1000000000 }}x{{ end }}{{ end }}"),
+		Entry("with", "{{ with .Name }}{{ . }}{{ end }}"),
+		Entry("if", "{{ if .Name }}x{{ end }}"),
+		Entry("define", `{{ define "x" }}y{{ end }}`),
+		Entry("template", `{{ template "path" }}`),
+		Entry("block", `{{ block "x" . }}y{{ end }}`),
+		Entry("function call", `{{ printf "%0999999999d" 1 }}`),
+		Entry("pipeline", "{{ .Name | len }}"),
+		Entry("variable", "{{ $name := .Name }}"),
+		Entry("dot", "{{ . }}"),
+		Entry("literal", `{{ "value" }}`),
+	)
+
+	It("should refuse parameters rendering too long values", func() {
+		cluster.Spec.Plugins[0].Parameters["path"] = strings.Repeat("{{ .UID }}", MaxRenderedParameterLength)
+		plugin := NewPlugin(cluster, "test-plugin")
+
+		_, err := plugin.RenderParameter("path")
+		Expect(err).To(MatchError(ErrRenderedParameterTooLong))
+	})
+
+	It("should fail rendering the major version when it cannot be detected", func() {
+		cluster.Spec.ImageName = ""
+		plugin := NewPlugin(cluster, "test-plugin")
+
+		_, err := plugin.RenderParameter("version")
+		Expect(err).To(MatchError(ErrUnknownPostgresMajorVersion))
+	})
+})
+
+var _ = Describe("GetPostgresMajorVersion", func() {
+	DescribeTable(
+		"detecting the major version",
+		func(cluster *apiv1.Cluster, expected int, succeeds bool) {
+			major, err := GetPostgresMajorVersion(cluster)
+			if !succeeds {
+				Expect(err).To(MatchError(ErrUnknownPostgresMajorVersion))
+				return
+			}
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(major).To(Equal(expected))
+		},
+		Entry(
+			"from the image catalog",
+			&apiv1.Cluster{Spec: apiv1.ClusterSpec{ImageCatalogRef: &apiv1.ImageCatalogRef{Major: 16}}},
+			16,
+			true,
+		),
+		Entry(
+			"from the image name",
+			&apiv1.Cluster{Spec: apiv1.ClusterSpec{ImageName: "postgresql:15.4-1"}},
+			15,
+			true,
+		),
+		Entry(
+			"from the status",
+			&apiv1.Cluster{Status: apiv1.ClusterStatus{PGDataImageInfo: &apiv1.ImageInfo{MajorVersion: 14}}},
+			14,
+			true,
+		),
+		Entry(
+			"from the image in the status",
+			&apiv1.Cluster{Status: apiv1.ClusterStatus{Image: "ghcr.io/cloudnative-pg/postgresql:18.0"}},
+			18,
+			true,
+		),
+		Entry(
+			"with an image tag not containing a version",
+			&apiv1.Cluster{Spec: apiv1.ClusterSpec{ImageName: "postgresql:latest"}},
+			0,
+			false,
+		),
+		Entry(
+			"without any information",
+			&apiv1.Cluster{},
+			0,
+			false,
+		),
+	)
+})
@@ -34,22 +34,10 @@ func getBackupGVK() schema.GroupVersionKind {
 
 // DecodeBackupLenient decodes a JSON representation of a backup.
 func DecodeBackupLenient(backupDefinition []byte) (*apiv1.Backup, error) {
-	var backup apiv1.Backup
-
-	if err := DecodeObjectLenient(backupDefinition, &backup); err != nil {
-		return nil, err
-	}
-
-	return &backup, nil
+	return Decode[apiv1.Backup](backupDefinition, LenientMode)
 }
 
 // DecodeBackupStrict decodes a JSON representation of a backup.
 func DecodeBackupStrict(backupDefinition []byte) (*apiv1.Backup, error) {
-	var backup apiv1.Backup
-
-	if err := DecodeObjectStrict(backupDefinition, &backup, getBackupGVK()); err != nil {
-		return nil, err
-	}
-
-	return &backup, nil
+	return Decode[apiv1.Backup](backupDefinition, StrictMode)
 }
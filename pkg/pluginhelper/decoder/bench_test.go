@@ -0,0 +1,101 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	"testing"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/object"
+)
+
+// decodeWithKindDetection is how the objects were decoded before the
+// fast path was available: detecting the kind first, then decoding
+// the whole object.
+func decodeWithKindDetection(
+	b *testing.B,
+	objectJSON []byte,
+	target runtime.Object,
+	expectedGVK schema.GroupVersionKind,
+) {
+	b.Helper()
+
+	if _, err := object.GetKind(objectJSON); err != nil {
+		b.Fatal(err)
+	}
+
+	if err := DecodeObjectStrict(objectJSON, target, expectedGVK); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkDecodeCluster(b *testing.B) {
+	clusterJSON := newLargeClusterJSON()
+
+	b.Run("encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			decodeWithKindDetection(b, clusterJSON, &apiv1.Cluster{}, getClusterGVK())
+		}
+	})
+
+	b.Run("fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := DecodeClusterFast(clusterJSON); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cache", func(b *testing.B) {
+		cache := NewClusterCache(DefaultCacheSize)
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := cache.Decode(clusterJSON); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkDecodePod(b *testing.B) {
+	podJSON := newLargePodJSON()
+
+	b.Run("encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			decodeWithKindDetection(b, podJSON, &corev1.Pod{}, getPodGVK())
+		}
+	})
+
+	b.Run("fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := DecodePodFast(podJSON); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,181 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultCacheSize is the number of objects kept by a Cache
+// when no size is specified.
+const DefaultCacheSize = 128
+
+type cacheKey struct {
+	uid             types.UID
+	resourceVersion string
+}
+
+type cacheEntry[PT any] struct {
+	key    cacheKey
+	digest [sha256.Size]byte
+	object PT
+}
+
+// Cache decodes JSON representations of objects of type T using the
+// fast decoding path, keeping the most recently decoded objects keyed
+// by their UID and resourceVersion. Objects lacking one of them are
+// decoded without being cached.
+//
+// The same UID and resourceVersion don't imply the same payload: the
+// new object of an update carries the resourceVersion of the old one,
+// and the operator may set defaults before sending an object. For this
+// reason, a cached object is reused only when the digest of the payload
+// matches, and is replaced otherwise. Every call returns a copy of the
+// cached object, which can be freely modified.
+// A Cache is safe for concurrent use.
+type Cache[T any, PT ClientObjectPointer[T]] struct {
+	expectedGVK schema.GroupVersionKind
+	size        int
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+}
+
+// NewCache creates a cache keeping at most size objects, i.e.
+//
+//	cache, err := decoder.NewCache[apiv1.Cluster](256)
+//
+// When size is not positive, DefaultCacheSize is used. The expected GVK
+// is derived from Scheme.
+func NewCache[T any, PT ClientObjectPointer[T]](size int) (*Cache[T, PT], error) {
+	expectedGVK, err := getGVKFromScheme(Scheme, PT(new(T)))
+	if err != nil {
+		return nil, err
+	}
+
+	return newCache[T, PT](expectedGVK, size), nil
+}
+
+// NewClusterCache creates a cache of Cluster objects keeping
+// at most size objects.
+func NewClusterCache(size int) *Cache[apiv1.Cluster, *apiv1.Cluster] {
+	return newCache[apiv1.Cluster](getClusterGVK(), size)
+}
+
+func newCache[T any, PT ClientObjectPointer[T]](expectedGVK schema.GroupVersionKind, size int) *Cache[T, PT] {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+
+	return &Cache[T, PT]{
+		expectedGVK: expectedGVK,
+		size:        size,
+		entries:     make(map[cacheKey]*list.Element, size),
+		lru:         list.New(),
+	}
+}
+
+// Decode decodes a JSON representation of an object, checking its GVK
+// like DecodeObjectStrict. The object is decoded only if the same
+// payload is not already in the cache.
+func (c *Cache[T, PT]) Decode(objectJSON []byte) (PT, error) {
+	header, err := readObjectHeader(objectJSON, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkGVK(header.GroupVersionKind(), c.expectedGVK); err != nil {
+		return nil, err
+	}
+
+	key := cacheKey{uid: header.UID, resourceVersion: header.ResourceVersion}
+	cacheable := key.uid != "" && key.resourceVersion != ""
+	var digest [sha256.Size]byte
+	if cacheable {
+		digest = sha256.Sum256(objectJSON)
+		if cached, ok := c.get(key, digest); ok {
+			return cached.DeepCopyObject().(PT), nil
+		}
+	}
+
+	result := PT(new(T))
+	if err := decodeObjectBody(objectJSON, result); err != nil {
+		return nil, err
+	}
+
+	if cacheable {
+		c.add(key, digest, result.DeepCopyObject().(PT))
+	}
+
+	return result, nil
+}
+
+// Len is the number of objects in the cache.
+func (c *Cache[T, PT]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+func (c *Cache[T, PT]) get(key cacheKey, digest [sha256.Size]byte) (PT, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry[PT])
+	if entry.digest != digest {
+		return nil, false
+	}
+
+	c.lru.MoveToFront(element)
+	return entry.object, true
+}
+
+func (c *Cache[T, PT]) add(key cacheKey, digest [sha256.Size]byte, object PT) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry[PT])
+		entry.digest = digest
+		entry.object = object
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry[PT]{key: key, digest: digest, object: object})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry[PT]).key)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cache", func() {
+	clusterJSON := func(uid, resourceVersion string) []byte {
+		return []byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"Cluster",` +
+			`"metadata":{"name":"test","uid":"` + uid + `","resourceVersion":"` + resourceVersion + `"}}`)
+	}
+
+	It("should cache the decoded objects by UID and resourceVersion", func() {
+		cache := NewClusterCache(10)
+
+		first, err := cache.Decode(clusterJSON("uid", "1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cache.Len()).To(Equal(1))
+
+		second, err := cache.Decode(clusterJSON("uid", "1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second).To(Equal(first))
+		Expect(cache.Len()).To(Equal(1))
+
+		_, err = cache.Decode(clusterJSON("uid", "2"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cache.Len()).To(Equal(2))
+	})
+
+	It("should not reuse a cached object when the payload changes", func() {
+		cache := NewClusterCache(10)
+		clusterWithInstances := func(instances string) []byte {
+			return []byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"Cluster",` +
+				`"metadata":{"name":"test","uid":"uid","resourceVersion":"7"},` +
+				`"spec":{"instances":` + instances + `}}`)
+		}
+
+		oldCluster, err := cache.Decode(clusterWithInstances("1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(oldCluster.Spec.Instances).To(Equal(1))
+
+		newCluster, err := cache.Decode(clusterWithInstances("5"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(newCluster.Spec.Instances).To(Equal(5))
+		Expect(cache.Len()).To(Equal(1))
+
+		cachedCluster, err := cache.Decode(clusterWithInstances("5"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cachedCluster.Spec.Instances).To(Equal(5))
+	})
+
+	It("should return copies of the cached objects", func() {
+		cache := NewClusterCache(10)
+
+		first, err := cache.Decode(clusterJSON("uid", "1"))
+		Expect(err).ToNot(HaveOccurred())
+		first.Name = "changed"
+
+		second, err := cache.Decode(clusterJSON("uid", "1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second.Name).To(Equal("test"))
+	})
+
+	It("should evict the least recently used objects", func() {
+		cache := NewClusterCache(2)
+
+		for _, resourceVersion := range []string{"1", "2", "1", "3"} {
+			_, err := cache.Decode(clusterJSON("uid", resourceVersion))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(cache.Len()).To(Equal(2))
+		Expect(cache.entries).To(HaveKey(cacheKey{uid: "uid", resourceVersion: "1"}))
+		Expect(cache.entries).To(HaveKey(cacheKey{uid: "uid", resourceVersion: "3"}))
+	})
+
+	It("should not cache objects without UID or resourceVersion", func() {
+		cache := NewClusterCache(10)
+
+		_, err := cache.Decode(clusterJSON("", "1"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = cache.Decode(clusterJSON("uid", ""))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cache.Len()).To(BeZero())
+	})
+
+	It("should check the object GVK", func() {
+		cache, err := NewCache[corev1.Pod](0)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = cache.Decode(clusterJSON("uid", "1"))
+		Expect(err).To(BeAssignableToTypeOf(&WrongObjectTypeError{}))
+		Expect(cache.Len()).To(BeZero())
+	})
+
+	It("should fail for types unknown to the scheme", func() {
+		_, err := NewCache[rbacv1.Role](0)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -34,22 +34,16 @@ func getClusterGVK() schema.GroupVersionKind {
 
 // DecodeClusterLenient decodes a JSON representation of a cluster.
 func DecodeClusterLenient(clusterJSON []byte) (*apiv1.Cluster, error) {
-	var result apiv1.Cluster
-
-	if err := DecodeObjectLenient(clusterJSON, &result); err != nil {
-		return nil, err
-	}
-
-	return &result, nil
+	return Decode[apiv1.Cluster](clusterJSON, LenientMode)
 }
 
 // DecodeClusterStrict decodes a JSON representation of a cluster.
 func DecodeClusterStrict(clusterJSON []byte) (*apiv1.Cluster, error) {
-	var result apiv1.Cluster
-
-	if err := DecodeObjectStrict(clusterJSON, &result, getClusterGVK()); err != nil {
-		return nil, err
-	}
+	return Decode[apiv1.Cluster](clusterJSON, StrictMode)
+}
 
-	return &result, nil
+// DecodeClusterFast decodes a JSON representation of a cluster using
+// the fast decoding path.
+func DecodeClusterFast(clusterJSON []byte) (*apiv1.Cluster, error) {
+	return DecodeFast[apiv1.Cluster](clusterJSON)
 }
@@ -0,0 +1,207 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// ContentType is the encoding of a serialized object.
+type ContentType string
+
+const (
+	// ContentTypeJSON is the JSON encoding.
+	ContentTypeJSON ContentType = "application/json"
+
+	// ContentTypeYAML is the YAML encoding.
+	ContentTypeYAML ContentType = "application/yaml"
+
+	// ContentTypeProtobuf is the Kubernetes protobuf encoding, which is
+	// only supported by the core Kubernetes types.
+	ContentTypeProtobuf ContentType = "application/vnd.kubernetes.protobuf"
+)
+
+var (
+	// ErrUnsupportedContentType is raised when the passed content type
+	// is not supported by the decoder.
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+
+	// ErrMalformedYAML is raised when the passed YAML representation
+	// cannot be parsed.
+	ErrMalformedYAML = errors.New("error unmarshalling object YAML")
+
+	// ErrMalformedProtobuf is raised when the passed protobuf
+	// representation cannot be parsed.
+	ErrMalformedProtobuf = errors.New("error unmarshalling object protobuf")
+
+	// ErrProtobufNotSupported is raised when decoding the protobuf
+	// representation of a type which has no protobuf encoding,
+	// such as the CloudNativePG ones.
+	ErrProtobufNotSupported = errors.New("type does not support the protobuf encoding")
+)
+
+// protobufPrefix is the magic number the Kubernetes protobuf
+// encoding starts with.
+var protobufPrefix = []byte("k8s\x00")
+
+// protobufUnmarshaler is implemented by the types having
+// a protobuf encoding.
+type protobufUnmarshaler interface {
+	Unmarshal(data []byte) error
+}
+
+// ParseContentType parses a media type, as found in the Content-Type
+// HTTP header, into a supported content type.
+func ParseContentType(mediaType string) (ContentType, error) {
+	parsedMediaType, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUnsupportedContentType, err)
+	}
+
+	switch parsedMediaType {
+	case string(ContentTypeJSON):
+		return ContentTypeJSON, nil
+	case string(ContentTypeYAML), "application/x-yaml", "text/yaml":
+		return ContentTypeYAML, nil
+	case string(ContentTypeProtobuf):
+		return ContentTypeProtobuf, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedContentType, parsedMediaType)
+	}
+}
+
+// DetectContentType detects the content type of a serialized object,
+// i.e. when reading fixtures from files.
+func DetectContentType(data []byte) ContentType {
+	trimmedData := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(data, protobufPrefix):
+		return ContentTypeProtobuf
+	case bytes.HasPrefix(trimmedData, []byte("{")):
+		return ContentTypeJSON
+	default:
+		return ContentTypeYAML
+	}
+}
+
+// DecodeContent decodes a representation of an object of type T
+// having the passed content type, i.e.
+//
+//	pod, err := decoder.DecodeContent[corev1.Pod](podYAML, decoder.ContentTypeYAML, decoder.StrictMode)
+//
+// The GVK is checked like in Decode. YAML representations are decoded
+// like JSON ones, and duplicated keys are rejected in strict modes.
+// Protobuf representations cannot carry unknown fields, so
+// StrictFieldsMode behaves like StrictMode for them.
+func DecodeContent[T any, PT ObjectPointer[T]](data []byte, contentType ContentType, mode Mode) (PT, error) {
+	return DecodeContentWithScheme[T, PT](data, contentType, Scheme, mode)
+}
+
+// DecodeContentWithScheme is like DecodeContent, using the passed scheme
+// to derive the expected GVK.
+func DecodeContentWithScheme[T any, PT ObjectPointer[T]](
+	data []byte,
+	contentType ContentType,
+	scheme *runtime.Scheme,
+	mode Mode,
+) (PT, error) {
+	switch contentType {
+	case ContentTypeJSON:
+		return DecodeWithScheme[T, PT](data, scheme, mode)
+
+	case ContentTypeYAML:
+		objectJSON, err := yamlToJSON(data, mode)
+		if err != nil {
+			return nil, err
+		}
+
+		return DecodeWithScheme[T, PT](objectJSON, scheme, mode)
+
+	case ContentTypeProtobuf:
+		return decodeProtobuf[T, PT](data, scheme, mode)
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+}
+
+func yamlToJSON(data []byte, mode Mode) ([]byte, error) {
+	if isEmptyInput(data) {
+		return nil, ErrEmptyInput
+	}
+
+	convert := yaml.YAMLToJSONStrict
+	if mode == LenientMode {
+		convert = yaml.YAMLToJSON
+	}
+
+	result, err := convert(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedYAML, err)
+	}
+
+	return result, nil
+}
+
+func decodeProtobuf[T any, PT ObjectPointer[T]](data []byte, scheme *runtime.Scheme, mode Mode) (PT, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	if !bytes.HasPrefix(data, protobufPrefix) {
+		return nil, fmt.Errorf("%w: missing the Kubernetes protobuf prefix", ErrMalformedProtobuf)
+	}
+
+	result := PT(new(T))
+	unmarshaler, ok := any(result).(protobufUnmarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrProtobufNotSupported, result)
+	}
+
+	var envelope runtime.Unknown
+	if err := envelope.Unmarshal(data[len(protobufPrefix):]); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedProtobuf, err)
+	}
+
+	receivedGVK := envelope.GroupVersionKind()
+	if mode != LenientMode {
+		expectedGVK, err := getGVKFromScheme(scheme, result)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkGVK(receivedGVK, expectedGVK); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := unmarshaler.Unmarshal(envelope.Raw); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedProtobuf, err)
+	}
+	result.GetObjectKind().SetGroupVersionKind(receivedGVK)
+
+	return result, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	"bytes"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const podYAML = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: test
+spec:
+  containers:
+  - name: postgres
+    image: postgres:17
+`
+
+func encodePodProtobuf(pod *corev1.Pod) []byte {
+	var buffer bytes.Buffer
+	Expect(protobuf.NewSerializer(Scheme, Scheme).Encode(pod, &buffer)).To(Succeed())
+	return buffer.Bytes()
+}
+
+var _ = Describe("Content type parsing", func() {
+	DescribeTable(
+		"should parse the supported media types",
+		func(mediaType string, expected ContentType) {
+			Expect(ParseContentType(mediaType)).To(Equal(expected))
+		},
+		Entry("JSON", "application/json; charset=utf-8", ContentTypeJSON),
+		Entry("YAML", "application/yaml", ContentTypeYAML),
+		Entry("legacy YAML", "application/x-yaml", ContentTypeYAML),
+		Entry("protobuf", "application/vnd.kubernetes.protobuf", ContentTypeProtobuf),
+	)
+
+	It("should fail for unsupported media types", func() {
+		_, err := ParseContentType("text/plain")
+		Expect(err).To(MatchError(ErrUnsupportedContentType))
+	})
+
+	It("should detect the content type", func() {
+		Expect(DetectContentType([]byte(` {"kind":"Pod"}`))).To(Equal(ContentTypeJSON))
+		Expect(DetectContentType([]byte(podYAML))).To(Equal(ContentTypeYAML))
+		Expect(DetectContentType(encodePodProtobuf(&corev1.Pod{}))).To(Equal(ContentTypeProtobuf))
+	})
+})
+
+var _ = Describe("DecodeContent", func() {
+	It("should decode YAML representations", func() {
+		pod, err := DecodeContent[corev1.Pod]([]byte(podYAML), ContentTypeYAML, StrictMode)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Name).To(Equal("test"))
+		Expect(pod.Spec.Containers).To(HaveLen(1))
+	})
+
+	It("should check the GVK of YAML representations", func() {
+		_, err := DecodeContent[apiv1.Cluster]([]byte(podYAML), ContentTypeYAML, StrictMode)
+		Expect(err).To(BeAssignableToTypeOf(&WrongObjectTypeError{}))
+	})
+
+	It("should report unknown fields of YAML representations", func() {
+		pod, err := DecodeContent[corev1.Pod]([]byte(podYAML+"unknown: true\n"), ContentTypeYAML, StrictFieldsMode)
+		Expect(err).To(MatchError(&UnknownFieldsError{Paths: []string{"unknown"}}))
+		Expect(pod.Name).To(Equal("test"))
+	})
+
+	It("should reject duplicated YAML keys only in strict modes", func() {
+		duplicatedYAML := []byte(podYAML + "kind: Pod\n")
+
+		_, err := DecodeContent[corev1.Pod](duplicatedYAML, ContentTypeYAML, StrictMode)
+		Expect(err).To(MatchError(ErrMalformedYAML))
+
+		_, err = DecodeContent[corev1.Pod](duplicatedYAML, ContentTypeYAML, LenientMode)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should decode protobuf representations", func() {
+		expected := &corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "postgres", Image: "postgres:17"}},
+			},
+		}
+
+		pod, err := DecodeContent[corev1.Pod](encodePodProtobuf(expected), ContentTypeProtobuf, StrictMode)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod).To(Equal(expected))
+	})
+
+	It("should check the GVK of protobuf representations", func() {
+		podProtobuf := encodePodProtobuf(&corev1.Pod{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}})
+
+		_, err := DecodeContent[corev1.ConfigMap](podProtobuf, ContentTypeProtobuf, StrictMode)
+		Expect(err).To(BeAssignableToTypeOf(&WrongObjectTypeError{}))
+
+		_, err = DecodeContent[corev1.Pod](encodePodProtobuf(&corev1.Pod{}), ContentTypeProtobuf, StrictMode)
+		Expect(err).To(MatchError(ErrMissingKind))
+	})
+
+	It("should fail decoding protobuf representations of types lacking it", func() {
+		podProtobuf := encodePodProtobuf(&corev1.Pod{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}})
+		_, err := DecodeContent[apiv1.Cluster](podProtobuf, ContentTypeProtobuf, LenientMode)
+		Expect(err).To(MatchError(ErrProtobufNotSupported))
+	})
+
+	DescribeTable(
+		"should fail for invalid inputs",
+		func(data string, contentType ContentType, expectedErr error) {
+			_, err := DecodeContent[corev1.Pod]([]byte(data), contentType, StrictMode)
+			Expect(err).To(MatchError(expectedErr))
+		},
+		Entry("empty YAML", "  ", ContentTypeYAML, ErrEmptyInput),
+		Entry("malformed YAML", "kind: [", ContentTypeYAML, ErrMalformedYAML),
+		Entry("empty protobuf", "", ContentTypeProtobuf, ErrEmptyInput),
+		Entry("not a protobuf", "{}", ContentTypeProtobuf, ErrMalformedProtobuf),
+		Entry("unsupported content type", "{}", ContentType("text/plain"), ErrUnsupportedContentType),
+	)
+})
@@ -20,29 +20,23 @@ SPDX-License-Identifier: Apache-2.0
 package decoder
 
 import (
+	"bytes"
 	"encoding/json"
-	"fmt"
+	"errors"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	kjson "sigs.k8s.io/json"
 )
 
-// WrongObjectTypeError is raised when the GVK of the passed JSON
-// object is different from the expected one.
-type WrongObjectTypeError struct {
-	expectedGVK schema.GroupVersionKind
-	receivedGVK schema.GroupVersionKind
-}
-
-// Error implements the error interface.
-func (e *WrongObjectTypeError) Error() string {
-	return fmt.Sprintf("received wrong GVK '%v' expected '%v'", e.receivedGVK.String(), e.expectedGVK.String())
-}
-
 // DecodeObjectLenient decodes a JSON representation of an object.
 func DecodeObjectLenient(objectJSON []byte, object runtime.Object) error {
+	if isEmptyInput(objectJSON) {
+		return ErrEmptyInput
+	}
+
 	if err := json.Unmarshal(objectJSON, object); err != nil {
-		return fmt.Errorf("error unmarshalling object JSON: %w", err)
+		return newMalformedJSONError(err)
 	}
 
 	return nil
@@ -54,12 +48,76 @@ func DecodeObjectStrict(objectJSON []byte, object runtime.Object, expectedGVK sc
 		return err
 	}
 
-	if object.GetObjectKind().GroupVersionKind() != expectedGVK {
+	if err := checkGVK(object.GetObjectKind().GroupVersionKind(), expectedGVK); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DecodeObjectStrictFields decodes a JSON representation of an object,
+// checking its GVK like DecodeObjectStrict and reporting the fields that
+// are not known to the target type with an UnknownFieldsError.
+//
+// When an UnknownFieldsError is returned, the object has been decoded.
+func DecodeObjectStrictFields(objectJSON []byte, object runtime.Object, expectedGVK schema.GroupVersionKind) error {
+	unknownFields, err := decodeObjectDetectingUnknownFields(objectJSON, object)
+	if err != nil {
+		return err
+	}
+
+	if err := checkGVK(object.GetObjectKind().GroupVersionKind(), expectedGVK); err != nil {
+		return err
+	}
+
+	if len(unknownFields) > 0 {
+		return &UnknownFieldsError{Paths: unknownFields}
+	}
+
+	return nil
+}
+
+// decodeObjectDetectingUnknownFields decodes a JSON representation of an
+// object, returning the paths of the fields unknown to the target type.
+func decodeObjectDetectingUnknownFields(objectJSON []byte, object runtime.Object) ([]string, error) {
+	if isEmptyInput(objectJSON) {
+		return nil, ErrEmptyInput
+	}
+
+	strictErrs, err := kjson.UnmarshalStrict(objectJSON, object, kjson.DisallowUnknownFields)
+	if err != nil {
+		return nil, newMalformedJSONError(err)
+	}
+
+	result := make([]string, 0, len(strictErrs))
+	for _, strictErr := range strictErrs {
+		var fieldErr kjson.FieldError
+		if errors.As(strictErr, &fieldErr) {
+			result = append(result, fieldErr.FieldPath())
+		} else {
+			result = append(result, strictErr.Error())
+		}
+	}
+
+	return result, nil
+}
+
+// checkGVK checks that the GVK of the decoded object is the expected one.
+func checkGVK(receivedGVK, expectedGVK schema.GroupVersionKind) error {
+	if receivedGVK.Kind == "" {
+		return ErrMissingKind
+	}
+
+	if receivedGVK != expectedGVK {
 		return &WrongObjectTypeError{
 			expectedGVK: expectedGVK,
-			receivedGVK: object.GetObjectKind().GroupVersionKind(),
+			receivedGVK: receivedGVK,
 		}
 	}
 
 	return nil
 }
+
+func isEmptyInput(objectJSON []byte) bool {
+	return len(bytes.TrimSpace(objectJSON)) == 0
+}
@@ -20,6 +20,7 @@ SPDX-License-Identifier: Apache-2.0
 package decoder
 
 import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
 	corev1 "k8s.io/api/core/v1"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -45,3 +46,46 @@ var _ = Describe("Generic decoder", func() {
 		Entry("should return error for invalid object JSON", []byte(`{"apiVersion":"v1","kind":}`), false),
 	)
 })
+
+var _ = Describe("Unknown fields detection", func() {
+	It("should report the paths of the unknown fields", func() {
+		var pod corev1.Pod
+		err := DecodeObjectStrictFields(
+			[]byte(`{"apiVersion":"v1","kind":"Pod","spec":{"newField":1,"containers":[{"name":"postgres","other":true}]}}`),
+			&pod,
+			getPodGVK(),
+		)
+
+		var unknownFieldsErr *UnknownFieldsError
+		Expect(err).To(BeAssignableToTypeOf(unknownFieldsErr))
+		Expect(err.(*UnknownFieldsError).Paths).To(ConsistOf("spec.newField", "spec.containers[0].other"))
+		Expect(pod.Spec.Containers).To(HaveLen(1))
+		Expect(pod.Spec.Containers[0].Name).To(Equal("postgres"))
+	})
+
+	It("should succeed when every field is known", func() {
+		var pod corev1.Pod
+		err := DecodeObjectStrictFields([]byte(`{"apiVersion":"v1","kind":"Pod","spec":{}}`), &pod, getPodGVK())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should check the GVK before reporting unknown fields", func() {
+		var pod corev1.Pod
+		err := DecodeObjectStrictFields([]byte(`{"apiVersion":"v1","kind":"Node","other":1}`), &pod, getPodGVK())
+		var wrongTypeErr *WrongObjectTypeError
+		Expect(err).To(BeAssignableToTypeOf(wrongTypeErr))
+	})
+
+	It("should return the decoded object together with the error in the generic decoder", func() {
+		cluster, err := Decode[apiv1.Cluster](
+			[]byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"Cluster","spec":{"instances":3,"newField":"x"}}`),
+			StrictFieldsMode,
+		)
+		Expect(err).To(MatchError(ContainSubstring("spec.newField")))
+		Expect(cluster).ToNot(BeNil())
+		Expect(cluster.Spec.Instances).To(Equal(3))
+
+		_, err = Decode[apiv1.Cluster]([]byte(`{"apiVersion":"v1","kind":}`), StrictFieldsMode)
+		Expect(err).To(HaveOccurred())
+	})
+})
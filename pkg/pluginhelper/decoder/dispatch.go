@@ -0,0 +1,223 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	"errors"
+	"fmt"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ErrUnregisteredKind is raised when the GVK of the passed JSON
+	// object has not been registered in the decoder registry.
+	ErrUnregisteredKind = errors.New("unregistered object kind")
+
+	// ErrNoHandler is raised when a Dispatcher receives an object
+	// for which no handler has been registered.
+	ErrNoHandler = errors.New("no handler registered for object kind")
+)
+
+// ClientObjectPointer is the constraint for pointers to Kubernetes
+// objects having metadata.
+type ClientObjectPointer[T any] interface {
+	*T
+	client.Object
+}
+
+// Registry maps the GVK of an object to the Go type used to decode it.
+// A Registry must be populated before being used, as registering types
+// is not safe for concurrent use.
+type Registry struct {
+	scheme    *runtime.Scheme
+	factories map[schema.GroupVersionKind]func() client.Object
+}
+
+// DefaultRegistry is the registry used by DecodeObject. It contains
+// Cluster, Backup and Pod.
+var DefaultRegistry = NewRegistry(Scheme)
+
+// NewRegistry creates an empty registry, using the passed scheme
+// to detect the GVK of the registered types.
+func NewRegistry(scheme *runtime.Scheme) *Registry {
+	return &Registry{
+		scheme:    scheme,
+		factories: make(map[schema.GroupVersionKind]func() client.Object),
+	}
+}
+
+// Register adds the type T to the registry, i.e.
+//
+//	err := decoder.Register[apiv1.ScheduledBackup](registry)
+//
+// The GVK of T is derived from the scheme of the registry.
+func Register[T any, PT ClientObjectPointer[T]](registry *Registry) error {
+	_, err := register[T, PT](registry)
+	return err
+}
+
+// IsRegistered checks if a type has been registered for the passed GVK.
+func (r *Registry) IsRegistered(gvk schema.GroupVersionKind) bool {
+	_, ok := r.factories[gvk]
+	return ok
+}
+
+// Decode decodes a JSON representation of an object whose type has
+// been registered, reading its apiVersion and kind to choose the
+// target type.
+func (r *Registry) Decode(objectJSON []byte) (client.Object, error) {
+	gvk, err := readGVK(objectJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.decode(gvk, objectJSON)
+}
+
+func (r *Registry) decode(gvk schema.GroupVersionKind, objectJSON []byte) (client.Object, error) {
+	factory, ok := r.factories[gvk]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnregisteredKind, gvk.String())
+	}
+
+	result := factory()
+	if err := decodeObjectBody(objectJSON, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DecodeObject decodes a JSON representation of an object whose type
+// has been registered in DefaultRegistry.
+func DecodeObject(objectJSON []byte) (client.Object, error) {
+	return DefaultRegistry.Decode(objectJSON)
+}
+
+// Dispatcher decodes a JSON representation of an object and invokes
+// the handler registered for its kind. It is meant to be used in the
+// lifecycle hooks implementation, i.e.
+//
+//	err := decoder.NewDispatcher().
+//		OnPod(func(pod *corev1.Pod) error { ... }).
+//		OnCluster(func(cluster *apiv1.Cluster) error { ... }).
+//		Dispatch(request.GetObjectDefinition())
+type Dispatcher struct {
+	registry *Registry
+	handlers map[schema.GroupVersionKind]func(client.Object) error
+}
+
+// NewDispatcher creates a dispatcher without handlers.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		registry: NewRegistry(Scheme),
+		handlers: make(map[schema.GroupVersionKind]func(client.Object) error),
+	}
+}
+
+// On registers the handler for the objects of type T, replacing the
+// previous one. It panics if T is not known to Scheme.
+func On[T any, PT ClientObjectPointer[T]](dispatcher *Dispatcher, handler func(PT) error) *Dispatcher {
+	gvk := mustRegister[T, PT](dispatcher.registry)
+	dispatcher.handlers[gvk] = func(object client.Object) error {
+		return handler(object.(PT))
+	}
+
+	return dispatcher
+}
+
+// OnPod registers the handler for the Pod objects.
+func (d *Dispatcher) OnPod(handler func(*corev1.Pod) error) *Dispatcher {
+	return On(d, handler)
+}
+
+// OnCluster registers the handler for the Cluster objects.
+func (d *Dispatcher) OnCluster(handler func(*apiv1.Cluster) error) *Dispatcher {
+	return On(d, handler)
+}
+
+// OnBackup registers the handler for the Backup objects.
+func (d *Dispatcher) OnBackup(handler func(*apiv1.Backup) error) *Dispatcher {
+	return On(d, handler)
+}
+
+// Dispatch decodes the passed JSON representation of an object and
+// invokes the handler registered for its kind, returning its error.
+// ErrNoHandler is raised when no handler matches the object kind.
+func (d *Dispatcher) Dispatch(objectJSON []byte) error {
+	gvk, err := readGVK(objectJSON)
+	if err != nil {
+		return err
+	}
+
+	handler, ok := d.handlers[gvk]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoHandler, gvk.String())
+	}
+
+	object, err := d.registry.decode(gvk, objectJSON)
+	if err != nil {
+		return err
+	}
+
+	return handler(object)
+}
+
+// readGVK reads the apiVersion and kind of a JSON representation
+// of an object.
+func readGVK(objectJSON []byte) (schema.GroupVersionKind, error) {
+	header, err := readObjectHeader(objectJSON, false)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+
+	if header.Kind == "" {
+		return schema.GroupVersionKind{}, ErrMissingKind
+	}
+
+	return header.GroupVersionKind(), nil
+}
+
+func register[T any, PT ClientObjectPointer[T]](registry *Registry) (schema.GroupVersionKind, error) {
+	gvk, err := getGVKFromScheme(registry.scheme, PT(new(T)))
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+
+	registry.factories[gvk] = func() client.Object {
+		return PT(new(T))
+	}
+
+	return gvk, nil
+}
+
+func mustRegister[T any, PT ClientObjectPointer[T]](registry *Registry) schema.GroupVersionKind {
+	gvk, err := register[T, PT](registry)
+	if err != nil {
+		panic(err)
+	}
+
+	return gvk
+}
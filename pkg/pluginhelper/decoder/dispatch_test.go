@@ -0,0 +1,130 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DecodeObject", func() {
+	DescribeTable(
+		"should decode the registered kinds",
+		func(objectJSON string, expected any) {
+			object, err := DecodeObject([]byte(objectJSON))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object).To(BeAssignableToTypeOf(expected))
+			Expect(object.GetName()).To(Equal("test"))
+		},
+		Entry("cluster", `{"apiVersion":"postgresql.cnpg.io/v1","kind":"Cluster","metadata":{"name":"test"}}`,
+			&apiv1.Cluster{}),
+		Entry("backup", `{"apiVersion":"postgresql.cnpg.io/v1","kind":"Backup","metadata":{"name":"test"}}`,
+			&apiv1.Backup{}),
+		Entry("pod", `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"test"}}`,
+			&corev1.Pod{}),
+	)
+
+	It("should fail for unregistered kinds", func() {
+		_, err := DecodeObject([]byte(`{"apiVersion":"v1","kind":"ConfigMap"}`))
+		Expect(err).To(MatchError(ErrUnregisteredKind))
+	})
+
+	It("should decode the kinds registered in a custom registry", func() {
+		registry := NewRegistry(Scheme)
+		Expect(Register[corev1.ConfigMap](registry)).To(Succeed())
+
+		object, err := registry.Decode([]byte(`{"apiVersion":"v1","kind":"ConfigMap","data":{"a":"b"}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(object.(*corev1.ConfigMap).Data).To(HaveKeyWithValue("a", "b"))
+	})
+
+	It("should fail registering types unknown to the scheme", func() {
+		Expect(Register[apiv1.Cluster](NewRegistry(runtime.NewScheme()))).ToNot(Succeed())
+	})
+
+	It("should report objects without a kind", func() {
+		_, err := DecodeObject([]byte(`{"apiVersion":"v1"}`))
+		Expect(err).To(MatchError(ErrMissingKind))
+	})
+})
+
+var _ = Describe("Dispatcher", func() {
+	var (
+		pods       []*corev1.Pod
+		clusters   []*apiv1.Cluster
+		dispatcher *Dispatcher
+	)
+
+	BeforeEach(func() {
+		pods = nil
+		clusters = nil
+		dispatcher = NewDispatcher().
+			OnPod(func(pod *corev1.Pod) error {
+				pods = append(pods, pod)
+				return nil
+			}).
+			OnCluster(func(cluster *apiv1.Cluster) error {
+				clusters = append(clusters, cluster)
+				return nil
+			})
+	})
+
+	It("should invoke the handler of the object kind", func() {
+		Expect(dispatcher.Dispatch([]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod"}}`))).
+			To(Succeed())
+		Expect(dispatcher.Dispatch(
+			[]byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"Cluster","metadata":{"name":"cluster"}}`))).
+			To(Succeed())
+
+		Expect(pods).To(HaveLen(1))
+		Expect(pods[0].Name).To(Equal("pod"))
+		Expect(clusters).To(HaveLen(1))
+		Expect(clusters[0].Name).To(Equal("cluster"))
+	})
+
+	It("should return the handler error", func() {
+		dispatcher.OnPod(func(*corev1.Pod) error {
+			return ErrEmptyInput
+		})
+		Expect(dispatcher.Dispatch([]byte(`{"apiVersion":"v1","kind":"Pod"}`))).To(MatchError(ErrEmptyInput))
+	})
+
+	It("should fail for kinds without a handler", func() {
+		err := dispatcher.Dispatch([]byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"Backup"}`))
+		Expect(err).To(MatchError(ErrNoHandler))
+		Expect(clusters).To(BeEmpty())
+	})
+
+	It("should support generic handlers", func() {
+		var configMap *corev1.ConfigMap
+		On(dispatcher, func(object *corev1.ConfigMap) error {
+			configMap = object
+			return nil
+		})
+
+		Expect(dispatcher.Dispatch([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm"}}`))).
+			To(Succeed())
+		Expect(configMap.Name).To(Equal("cm"))
+	})
+})
@@ -17,6 +17,6 @@ limitations under the License.
 SPDX-License-Identifier: Apache-2.0
 */
 
-// Package decoder contains the functions that decode a JSON
-// stream into a structured Kubernetes resource
+// Package decoder contains the functions that decode a JSON, YAML
+// or protobuf stream into a structured Kubernetes resource
 package decoder
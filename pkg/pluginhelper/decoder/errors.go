@@ -0,0 +1,125 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kjson "sigs.k8s.io/json"
+)
+
+var (
+	// ErrEmptyInput is raised when an empty JSON representation is
+	// passed to the decoder.
+	ErrEmptyInput = errors.New("empty object JSON")
+
+	// ErrMissingKind is raised when the decoded JSON representation
+	// has no kind.
+	ErrMissingKind = errors.New("object JSON has no kind")
+
+	// ErrNoCompatibleGVK is raised when no compatible GVK is passed to
+	// the version-skew aware decoder.
+	ErrNoCompatibleGVK = errors.New("no compatible GVK passed")
+)
+
+// WrongObjectTypeError is raised when the GVK of the passed JSON
+// object is different from the expected one.
+type WrongObjectTypeError struct {
+	expectedGVK schema.GroupVersionKind
+	receivedGVK schema.GroupVersionKind
+}
+
+// Error implements the error interface.
+func (e *WrongObjectTypeError) Error() string {
+	return fmt.Sprintf("received wrong GVK '%v' expected '%v'", e.receivedGVK.String(), e.expectedGVK.String())
+}
+
+// ExpectedGVK is the GVK the decoder was expecting.
+func (e *WrongObjectTypeError) ExpectedGVK() schema.GroupVersionKind {
+	return e.expectedGVK
+}
+
+// ReceivedGVK is the GVK of the passed JSON object.
+func (e *WrongObjectTypeError) ReceivedGVK() schema.GroupVersionKind {
+	return e.receivedGVK
+}
+
+// UnknownFieldsError is raised when the passed JSON object contains
+// fields that are not known to the target type. This usually means that
+// the operator is using a newer API version than the one the plugin
+// has been built with.
+//
+// The object is decoded anyway, ignoring the unknown fields, to let
+// the caller decide whether to fail or to just warn.
+type UnknownFieldsError struct {
+	// Paths are the paths of the unknown fields, i.e. `spec.newField`
+	Paths []string
+}
+
+// Error implements the error interface.
+func (e *UnknownFieldsError) Error() string {
+	return fmt.Sprintf("unknown fields: %s", strings.Join(e.Paths, ", "))
+}
+
+// MalformedJSONError is raised when the passed JSON representation
+// cannot be parsed, or its values don't match the types of the
+// target object.
+type MalformedJSONError struct {
+	// Offset is the position in the input after which the error
+	// has been detected, or -1 when it is not known
+	Offset int64
+
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements the error interface.
+func (e *MalformedJSONError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("error unmarshalling object JSON: %v", e.Err)
+	}
+
+	return fmt.Sprintf("error unmarshalling object JSON at offset %d: %v", e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *MalformedJSONError) Unwrap() error {
+	return e.Err
+}
+
+func newMalformedJSONError(err error) *MalformedJSONError {
+	result := &MalformedJSONError{
+		Offset: -1,
+		Err:    err,
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if isSyntaxError, offset := kjson.SyntaxErrorOffset(err); isSyntaxError {
+		result.Offset = offset
+	} else if errors.As(err, &typeErr) {
+		result.Offset = typeErr.Offset
+	}
+
+	return result
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Decoder errors", func() {
+	It("should expose the GVKs of wrong object types", func() {
+		_, err := DecodeClusterStrict([]byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"Backup"}`))
+		var wrongTypeErr *WrongObjectTypeError
+		Expect(err).To(BeAssignableToTypeOf(wrongTypeErr))
+		wrongTypeErr = err.(*WrongObjectTypeError)
+		Expect(wrongTypeErr.ExpectedGVK()).To(Equal(getClusterGVK()))
+		Expect(wrongTypeErr.ReceivedGVK()).To(Equal(getBackupGVK()))
+	})
+
+	It("should report empty inputs", func() {
+		_, err := DecodeClusterStrict([]byte("  \n"))
+		Expect(err).To(MatchError(ErrEmptyInput))
+
+		_, err = Decode[apiv1.Cluster](nil, StrictFieldsMode)
+		Expect(err).To(MatchError(ErrEmptyInput))
+	})
+
+	It("should report objects without a kind", func() {
+		_, err := DecodeClusterStrict([]byte(`{"apiVersion":"postgresql.cnpg.io/v1"}`))
+		Expect(err).To(MatchError(ErrMissingKind))
+	})
+
+	DescribeTable(
+		"should report malformed JSON with its offset",
+		func(objectJSON string, mode Mode, offset int64) {
+			_, err := Decode[apiv1.Cluster]([]byte(objectJSON), mode)
+			var malformedErr *MalformedJSONError
+			Expect(err).To(BeAssignableToTypeOf(malformedErr))
+			Expect(err.(*MalformedJSONError).Offset).To(Equal(offset))
+		},
+		Entry("syntax error", `{"kind":}`, StrictMode, int64(9)),
+		Entry("syntax error with the strict fields mode", `{"kind":}`, StrictFieldsMode, int64(9)),
+		Entry("type error", `{"kind":"Cluster","spec":{"instances":"three"}}`, LenientMode, int64(45)),
+	)
+})
+
+var _ = Describe("ToGRPCStatus", func() {
+	getViolations := func(details []any) []*errdetails.BadRequest_FieldViolation {
+		Expect(details).To(HaveLen(1))
+		badRequest, ok := details[0].(*errdetails.BadRequest)
+		Expect(ok).To(BeTrue())
+		return badRequest.GetFieldViolations()
+	}
+
+	It("should return nil for nil errors", func() {
+		Expect(ToGRPCStatus(nil)).To(BeNil())
+	})
+
+	It("should convert wrong object type errors", func() {
+		_, err := DecodeClusterStrict([]byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"Backup"}`))
+		st := ToGRPCStatus(err)
+		Expect(st.Code()).To(Equal(codes.InvalidArgument))
+		violations := getViolations(st.Details())
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].GetField()).To(Equal("kind"))
+	})
+
+	It("should convert unknown fields errors", func() {
+		_, err := Decode[apiv1.Cluster](
+			[]byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"Cluster","a":1,"b":2}`),
+			StrictFieldsMode,
+		)
+		violations := getViolations(ToGRPCStatus(err).Details())
+		Expect(violations).To(HaveLen(2))
+		Expect(violations[0].GetField()).To(Equal("a"))
+		Expect(violations[1].GetField()).To(Equal("b"))
+	})
+
+	It("should convert malformed JSON errors", func() {
+		_, err := DecodeClusterStrict([]byte(`{"kind":}`))
+		violations := getViolations(ToGRPCStatus(err).Details())
+		Expect(violations[0].GetDescription()).To(ContainSubstring("at offset 9"))
+	})
+
+	It("should convert other errors without details", func() {
+		_, err := DecodeClusterStrict(nil)
+		st := ToGRPCStatus(err)
+		Expect(st.Code()).To(Equal(codes.InvalidArgument))
+		Expect(st.Details()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,142 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// fastJSON is the JSON implementation used by the fast decoding path.
+// It behaves like encoding/json while avoiding most of its reflection
+// overhead.
+var fastJSON = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// DecodeObjectFast decodes a JSON representation of an object, checking
+// its GVK like DecodeObjectStrict.
+//
+// The type metadata is read by scanning the top-level fields of the
+// object without decoding them, and the GVK is checked before decoding
+// the object body. This makes it noticeably faster than
+// DecodeObjectStrict for large payloads.
+func DecodeObjectFast(objectJSON []byte, object runtime.Object, expectedGVK schema.GroupVersionKind) error {
+	header, err := readObjectHeader(objectJSON, false)
+	if err != nil {
+		return err
+	}
+
+	if err := checkGVK(header.GroupVersionKind(), expectedGVK); err != nil {
+		return err
+	}
+
+	return decodeObjectBody(objectJSON, object)
+}
+
+// DecodeFast decodes a JSON representation of an object of type T,
+// using the fast decoding path. The expected GVK is derived from
+// Scheme, i.e.
+//
+//	cluster, err := decoder.DecodeFast[apiv1.Cluster](clusterJSON)
+func DecodeFast[T any, PT ObjectPointer[T]](objectJSON []byte) (PT, error) {
+	result := PT(new(T))
+
+	expectedGVK, err := getGVKFromScheme(Scheme, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := DecodeObjectFast(objectJSON, result, expectedGVK); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// objectHeader is the information of an object that can be
+// read without decoding its body.
+type objectHeader struct {
+	metav1.TypeMeta
+	UID             types.UID
+	ResourceVersion string
+}
+
+// readObjectHeader reads the type metadata of a JSON representation of
+// an object and, when withMetadata is true, its UID and resourceVersion.
+// The other fields are skipped and the scan stops as soon as the
+// requested information is read.
+func readObjectHeader(objectJSON []byte, withMetadata bool) (objectHeader, error) {
+	var result objectHeader
+
+	if isEmptyInput(objectJSON) {
+		return result, ErrEmptyInput
+	}
+
+	iter := fastJSON.BorrowIterator(objectJSON)
+	defer fastJSON.ReturnIterator(iter)
+
+	metadataRead := !withMetadata
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {
+		switch {
+		case field == "apiVersion":
+			result.APIVersion = iter.ReadString()
+		case field == "kind":
+			result.Kind = iter.ReadString()
+		case field == "metadata" && withMetadata:
+			readObjectMetadata(iter, &result)
+			metadataRead = true
+		default:
+			iter.Skip()
+		}
+
+		return iter.Error == nil && (result.APIVersion == "" || result.Kind == "" || !metadataRead)
+	})
+
+	if iter.Error != nil {
+		return result, newMalformedJSONError(iter.Error)
+	}
+
+	return result, nil
+}
+
+func readObjectMetadata(iter *jsoniter.Iterator, header *objectHeader) {
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {
+		switch field {
+		case "uid":
+			header.UID = types.UID(iter.ReadString())
+		case "resourceVersion":
+			header.ResourceVersion = iter.ReadString()
+		default:
+			iter.Skip()
+		}
+
+		return iter.Error == nil
+	})
+}
+
+func decodeObjectBody(objectJSON []byte, object runtime.Object) error {
+	if err := fastJSON.Unmarshal(objectJSON, object); err != nil {
+		return newMalformedJSONError(err)
+	}
+
+	return nil
+}
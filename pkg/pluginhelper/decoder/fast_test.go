@@ -0,0 +1,190 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	"github.com/onsi/gomega/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newLargeClusterJSON creates the JSON representation of a Cluster
+// resembling the ones found in production environments.
+func newLargeClusterJSON() []byte {
+	cluster := apiv1.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apiv1.SchemeGroupVersion.String(),
+			Kind:       apiv1.ClusterKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "cluster-example",
+			Namespace:       "default",
+			UID:             "4e5f7c1a-59d6-4d0d-9b1b-1f0e0f5e6a7b",
+			ResourceVersion: "123456",
+			Labels:          map[string]string{"app": "example"},
+			Annotations:     map[string]string{"cnpg.io/reloadedAt": "2024-01-01T00:00:00Z"},
+		},
+		Spec: apiv1.ClusterSpec{
+			Instances: 3,
+			ImageName: "ghcr.io/cloudnative-pg/postgresql:17.2",
+			PostgresConfiguration: apiv1.PostgresConfiguration{
+				Parameters: make(map[string]string),
+			},
+			StorageConfiguration: apiv1.StorageConfiguration{
+				Size: "10Gi",
+			},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+			Plugins: []apiv1.PluginConfiguration{
+				{
+					Name:       "plugin.cnpg.io",
+					Parameters: map[string]string{"destination": "s3://bucket/path"},
+				},
+			},
+		},
+		Status: apiv1.ClusterStatus{
+			CurrentPrimary: "cluster-example-1",
+			Phase:          "Cluster in healthy state",
+			InstanceNames:  []string{"cluster-example-1", "cluster-example-2", "cluster-example-3"},
+		},
+	}
+
+	for i := range 200 {
+		cluster.Spec.PostgresConfiguration.Parameters[fmt.Sprintf("custom.parameter_%d", i)] = fmt.Sprintf("value-%d", i)
+		cluster.Spec.PostgresConfiguration.PgHBA = append(
+			cluster.Spec.PostgresConfiguration.PgHBA,
+			fmt.Sprintf("host all user_%d 10.0.%d.0/24 scram-sha-256", i, i%256),
+		)
+		cluster.Spec.Env = append(cluster.Spec.Env, corev1.EnvVar{
+			Name:  fmt.Sprintf("ENV_%d", i),
+			Value: fmt.Sprintf("value-%d", i),
+		})
+	}
+
+	result, err := json.Marshal(cluster)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// newLargePodJSON creates the JSON representation of a Pod
+// resembling the ones created for a Cluster instance.
+func newLargePodJSON() []byte {
+	pod := corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Pod",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "cluster-example-1",
+			Namespace:       "default",
+			UID:             "1b2c3d4e-59d6-4d0d-9b1b-1f0e0f5e6a7b",
+			ResourceVersion: "654321",
+		},
+	}
+
+	for i := range 4 {
+		container := corev1.Container{
+			Name:  fmt.Sprintf("container-%d", i),
+			Image: "ghcr.io/cloudnative-pg/postgresql:17.2",
+		}
+		for j := range 50 {
+			container.Env = append(container.Env, corev1.EnvVar{
+				Name:  fmt.Sprintf("ENV_%d", j),
+				Value: fmt.Sprintf("value-%d", j),
+			})
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      fmt.Sprintf("volume-%d", j),
+				MountPath: fmt.Sprintf("/volumes/%d", j),
+			})
+		}
+		pod.Spec.Containers = append(pod.Spec.Containers, container)
+	}
+
+	result, err := json.Marshal(pod)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+var _ = Describe("Fast decoding", func() {
+	It("should decode Clusters like the strict decoder", func() {
+		clusterJSON := newLargeClusterJSON()
+
+		expected, err := DecodeClusterStrict(clusterJSON)
+		Expect(err).ToNot(HaveOccurred())
+
+		cluster, err := DecodeClusterFast(clusterJSON)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cluster).To(Equal(expected))
+	})
+
+	It("should decode Pods like the strict decoder", func() {
+		podJSON := newLargePodJSON()
+
+		expected, err := DecodePodJSON(podJSON)
+		Expect(err).ToNot(HaveOccurred())
+
+		pod, err := DecodePodFast(podJSON)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod).To(Equal(expected))
+	})
+
+	It("should read the type metadata wherever it is", func() {
+		cluster, err := DecodeClusterFast(
+			[]byte(`{"metadata":{"name":"test"},"kind":"Cluster","apiVersion":"postgresql.cnpg.io/v1"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cluster.Name).To(Equal("test"))
+	})
+
+	DescribeTable(
+		"should fail like the strict decoder",
+		func(objectJSON string, expectedErr types.GomegaMatcher) {
+			_, err := DecodeClusterFast([]byte(objectJSON))
+			Expect(err).To(expectedErr)
+		},
+		Entry("empty input", " ", MatchError(ErrEmptyInput)),
+		Entry("missing kind", `{"apiVersion":"postgresql.cnpg.io/v1"}`, MatchError(ErrMissingKind)),
+		Entry("wrong kind", `{"apiVersion":"postgresql.cnpg.io/v1","kind":"Backup"}`,
+			BeAssignableToTypeOf(&WrongObjectTypeError{})),
+		Entry("malformed type metadata", `{"apiVersion":"postgresql.cnpg.io/v1","kind":1}`,
+			BeAssignableToTypeOf(&MalformedJSONError{})),
+		Entry("malformed body", `{"apiVersion":"postgresql.cnpg.io/v1","kind":"Cluster","spec":{"instances":"a"}}`,
+			BeAssignableToTypeOf(&MalformedJSONError{})),
+		Entry("trailing data", `{"apiVersion":"postgresql.cnpg.io/v1","kind":"Cluster"} {}`,
+			BeAssignableToTypeOf(&MalformedJSONError{})),
+	)
+})
@@ -0,0 +1,112 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Mode controls how strictly an object is decoded.
+type Mode int
+
+const (
+	// LenientMode decodes the object without checking its GVK.
+	LenientMode Mode = iota
+
+	// StrictMode decodes the object checking that its GVK matches
+	// the one registered in the scheme for the target type.
+	StrictMode
+
+	// StrictFieldsMode decodes the object like StrictMode, and reports
+	// the fields that are unknown to the target type with an
+	// UnknownFieldsError. In that case, the decoded object is
+	// returned together with the error.
+	StrictFieldsMode
+)
+
+// ObjectPointer is the constraint for pointers to Kubernetes objects.
+type ObjectPointer[T any] interface {
+	*T
+	runtime.Object
+}
+
+// Decode decodes a JSON representation of an object of type T, i.e.
+//
+//	cluster, err := decoder.Decode[apiv1.Cluster](clusterJSON, decoder.StrictMode)
+//
+// In strict mode, the expected GVK is derived from Scheme.
+func Decode[T any, PT ObjectPointer[T]](objectJSON []byte, mode Mode) (PT, error) {
+	return DecodeWithScheme[T, PT](objectJSON, Scheme, mode)
+}
+
+// DecodeWithScheme is like Decode, using the passed scheme to derive
+// the expected GVK.
+func DecodeWithScheme[T any, PT ObjectPointer[T]](
+	objectJSON []byte,
+	scheme *runtime.Scheme,
+	mode Mode,
+) (PT, error) {
+	result := PT(new(T))
+
+	if mode == LenientMode {
+		if err := DecodeObjectLenient(objectJSON, result); err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	}
+
+	expectedGVK, err := getGVKFromScheme(scheme, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == StrictFieldsMode {
+		err := DecodeObjectStrictFields(objectJSON, result, expectedGVK)
+		var unknownFieldsErr *UnknownFieldsError
+		if errors.As(err, &unknownFieldsErr) {
+			return result, err
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	}
+
+	if err := DecodeObjectStrict(objectJSON, result, expectedGVK); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func getGVKFromScheme(scheme *runtime.Scheme, object runtime.Object) (schema.GroupVersionKind, error) {
+	gvks, _, err := scheme.ObjectKinds(object)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("while detecting the expected GVK: %w", err)
+	}
+
+	return gvks[0], nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Decode", func() {
+	It("should decode CloudNativePG kinds", func() {
+		scheduledBackup, err := Decode[apiv1.ScheduledBackup](
+			[]byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"ScheduledBackup","spec":{"schedule":"@daily"}}`),
+			StrictMode,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scheduledBackup.Spec.Schedule).To(Equal("@daily"))
+
+		pooler, err := Decode[apiv1.Pooler](
+			[]byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"Pooler"}`),
+			StrictMode,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pooler.Kind).To(Equal(apiv1.PoolerKind))
+	})
+
+	It("should decode core kinds", func() {
+		secret, err := Decode[corev1.Secret]([]byte(`{"apiVersion":"v1","kind":"Secret"}`), StrictMode)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(secret.Kind).To(Equal("Secret"))
+
+		job, err := Decode[batchv1.Job]([]byte(`{"apiVersion":"batch/v1","kind":"Job"}`), StrictMode)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(job.Kind).To(Equal("Job"))
+	})
+
+	It("should refuse objects with a wrong GVK in strict mode", func() {
+		_, err := Decode[apiv1.Database]([]byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"Pooler"}`), StrictMode)
+		var wrongTypeErr *WrongObjectTypeError
+		Expect(err).To(BeAssignableToTypeOf(wrongTypeErr))
+	})
+
+	It("should accept objects with a wrong GVK in lenient mode", func() {
+		database, err := Decode[apiv1.Database](
+			[]byte(`{"apiVersion":"postgresql.cnpg.io/v1","kind":"Pooler"}`),
+			LenientMode,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(database.Kind).To(Equal(apiv1.PoolerKind))
+	})
+
+	It("should fail in strict mode when the type is not registered in the scheme", func() {
+		_, err := DecodeWithScheme[corev1.Pod](
+			[]byte(`{"apiVersion":"v1","kind":"Pod"}`),
+			runtime.NewScheme(),
+			StrictMode,
+		)
+		Expect(err).To(MatchError(ContainSubstring("while detecting the expected GVK")))
+	})
+})
@@ -0,0 +1,90 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ToGRPCStatus converts an error raised by the decoder into an
+// InvalidArgument gRPC status, describing the offending fields in a
+// BadRequest detail. Nil is returned for nil errors.
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	var wrongTypeErr *WrongObjectTypeError
+	var unknownFieldsErr *UnknownFieldsError
+	var malformedErr *MalformedJSONError
+	switch {
+	case errors.As(err, &wrongTypeErr):
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field: "kind",
+			Description: fmt.Sprintf(
+				"expected %s, received %s",
+				wrongTypeErr.ExpectedGVK().String(),
+				wrongTypeErr.ReceivedGVK().String(),
+			),
+		})
+
+	case errors.As(err, &unknownFieldsErr):
+		for _, path := range unknownFieldsErr.Paths {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       path,
+				Description: "unknown field",
+			})
+		}
+
+	case errors.Is(err, ErrMissingKind):
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "kind",
+			Description: "missing kind",
+		})
+
+	case errors.As(err, &malformedErr):
+		description := malformedErr.Err.Error()
+		if malformedErr.Offset >= 0 {
+			description = fmt.Sprintf("at offset %d: %s", malformedErr.Offset, description)
+		}
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Description: description,
+		})
+	}
+
+	result := status.New(codes.InvalidArgument, err.Error())
+	if len(violations) == 0 {
+		return result
+	}
+
+	withDetails, detailsErr := result.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailsErr != nil {
+		return result
+	}
+
+	return withDetails
+}
@@ -34,11 +34,11 @@ func getPodGVK() schema.GroupVersionKind {
 
 // DecodePodJSON decodes a JSON representation of a pod.
 func DecodePodJSON(podJSON []byte) (*corev1.Pod, error) {
-	var result corev1.Pod
-
-	if err := DecodeObjectStrict(podJSON, &result, getPodGVK()); err != nil {
-		return nil, err
-	}
+	return Decode[corev1.Pod](podJSON, StrictMode)
+}
 
-	return &result, nil
+// DecodePodFast decodes a JSON representation of a pod using
+// the fast decoding path.
+func DecodePodFast(podJSON []byte) (*corev1.Pod, error) {
+	return DecodeFast[corev1.Pod](podJSON)
 }
@@ -0,0 +1,362 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SkewAwareObject is an object decoded from a JSON representation that may
+// have been produced by a newer version of the operator. It keeps track of
+// the received GVK and of the fields unknown to the target type, so that
+// they are not lost when the object is serialized again.
+type SkewAwareObject[T any, PT ObjectPointer[T]] struct {
+	// Object is the decoded object
+	Object PT
+
+	// ReceivedGVK is the GVK of the received JSON representation
+	ReceivedGVK schema.GroupVersionKind
+
+	// UnknownFields are the fields unknown to the target type, indexed
+	// by their path, i.e. `spec.newField` or `spec.containers[0].newField`
+	UnknownFields map[string]json.RawMessage
+
+	// listElementKeys are, for each unknown field, the merge keys of the
+	// list elements in its path, as found in the received object
+	listElementKeys map[string][]listElementKey
+}
+
+// listElementMergeKeys are the fields identifying the elements of a list,
+// in order of preference.
+var listElementMergeKeys = []string{"name", "mountPath"}
+
+// listElementKey identifies a list element by the value of its merge key.
+// An empty field means the element could not be identified.
+type listElementKey struct {
+	field string
+	value string
+}
+
+// DecodeSkewAware decodes a JSON representation of an object accepting any
+// of the passed GVKs and preserving the fields unknown to the target type.
+func DecodeSkewAware[T any, PT ObjectPointer[T]](
+	objectJSON []byte,
+	compatibleGVKs ...schema.GroupVersionKind,
+) (*SkewAwareObject[T, PT], error) {
+	if len(compatibleGVKs) == 0 {
+		return nil, ErrNoCompatibleGVK
+	}
+
+	result := &SkewAwareObject[T, PT]{
+		Object: PT(new(T)),
+	}
+
+	unknownFields, err := decodeObjectDetectingUnknownFields(objectJSON, result.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	result.ReceivedGVK = result.Object.GetObjectKind().GroupVersionKind()
+	if result.ReceivedGVK.Kind == "" {
+		return nil, ErrMissingKind
+	}
+	if !slices.Contains(compatibleGVKs, result.ReceivedGVK) {
+		return nil, &WrongObjectTypeError{
+			expectedGVK: compatibleGVKs[0],
+			receivedGVK: result.ReceivedGVK,
+		}
+	}
+
+	if err := result.collectUnknownFields(objectJSON, unknownFields); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DecodeClusterSkewAware decodes a JSON representation of a cluster
+// accepting any of the passed GVKs, or the GVK of the API the plugin has
+// been built with if none is passed.
+func DecodeClusterSkewAware(
+	clusterJSON []byte,
+	compatibleGVKs ...schema.GroupVersionKind,
+) (*SkewAwareObject[apiv1.Cluster, *apiv1.Cluster], error) {
+	if len(compatibleGVKs) == 0 {
+		compatibleGVKs = []schema.GroupVersionKind{getClusterGVK()}
+	}
+
+	return DecodeSkewAware[apiv1.Cluster](clusterJSON, compatibleGVKs...)
+}
+
+// collectUnknownFields extracts the values of the fields at the passed
+// paths from the JSON representation of the object, together with the
+// merge keys of the list elements containing them.
+func (o *SkewAwareObject[T, PT]) collectUnknownFields(objectJSON []byte, paths []string) error {
+	o.UnknownFields = make(map[string]json.RawMessage, len(paths))
+	o.listElementKeys = make(map[string][]listElementKey, len(paths))
+	if len(paths) == 0 {
+		return nil
+	}
+
+	var content any
+	if err := unmarshalUsingNumbers(objectJSON, &content); err != nil {
+		return newMalformedJSONError(err)
+	}
+
+	for _, path := range paths {
+		steps := parseJSONFieldPath(path)
+		value, ok := getJSONPathValue(content, steps)
+		if !ok {
+			continue
+		}
+
+		rawValue, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("while marshalling unknown field %q: %w", path, err)
+		}
+		o.UnknownFields[path] = rawValue
+		o.listElementKeys[path] = getListElementKeys(content, steps)
+	}
+
+	return nil
+}
+
+// Marshal serializes the object using the received GVK and restoring the
+// unknown fields. Unknown fields that cannot be restored are dropped, see
+// MarshalDetectingDroppedFields.
+func (o *SkewAwareObject[T, PT]) Marshal() ([]byte, error) {
+	data, _, err := o.MarshalDetectingDroppedFields()
+	return data, err
+}
+
+// MarshalDetectingDroppedFields serializes the object like Marshal,
+// returning the sorted paths of the unknown fields that have been dropped.
+//
+// Unknown fields contained in list elements are restored into the element
+// having the same merge key (`name` or `mountPath`) as the one they were
+// received in, whatever its current position in the list. Unknown fields
+// are dropped when their parent has been removed from the object, or when
+// they are contained in a list element without a merge key, as they
+// cannot be safely attached back to it.
+func (o *SkewAwareObject[T, PT]) MarshalDetectingDroppedFields() ([]byte, []string, error) {
+	objectJSON, err := json.Marshal(o.Object)
+	if err != nil {
+		return nil, nil, fmt.Errorf("while marshalling object: %w", err)
+	}
+
+	var content map[string]any
+	if err := unmarshalUsingNumbers(objectJSON, &content); err != nil {
+		return nil, nil, fmt.Errorf("while unmarshalling object: %w", err)
+	}
+
+	if !o.ReceivedGVK.Empty() {
+		content["apiVersion"], content["kind"] = o.ReceivedGVK.ToAPIVersionAndKind()
+	}
+
+	var droppedFields []string
+	for path, rawValue := range o.UnknownFields {
+		var value any
+		if err := unmarshalUsingNumbers(rawValue, &value); err != nil {
+			return nil, nil, fmt.Errorf("while unmarshalling unknown field %q: %w", path, err)
+		}
+
+		steps, ok := resolveListElementKeys(content, parseJSONFieldPath(path), o.listElementKeys[path])
+		if !ok || !setJSONPathValue(content, steps, value) {
+			droppedFields = append(droppedFields, path)
+		}
+	}
+	slices.Sort(droppedFields)
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, droppedFields, nil
+}
+
+// unmarshalUsingNumbers decodes a JSON value keeping numbers as
+// json.Number, so that they are not altered by a round trip.
+func unmarshalUsingNumbers(data []byte, target any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(target)
+}
+
+// getListElementKeys gets the merge keys of the list elements
+// in the passed path, in order.
+func getListElementKeys(content any, path []any) []listElementKey {
+	var result []listElementKey
+	for i, step := range path {
+		if _, ok := step.(int); !ok {
+			continue
+		}
+
+		element, _ := getJSONPathValue(content, path[:i+1])
+		result = append(result, getListElementKey(element))
+	}
+
+	return result
+}
+
+func getListElementKey(element any) listElementKey {
+	object, ok := element.(map[string]any)
+	if !ok {
+		return listElementKey{}
+	}
+
+	for _, field := range listElementMergeKeys {
+		if value, ok := object[field].(string); ok {
+			return listElementKey{field: field, value: value}
+		}
+	}
+
+	return listElementKey{}
+}
+
+// resolveListElementKeys replaces the list indexes in the passed path
+// with the current indexes of the elements having the passed merge keys.
+// It fails if any of the elements cannot be univocally found.
+func resolveListElementKeys(content any, path []any, keys []listElementKey) ([]any, bool) {
+	result := slices.Clone(path)
+	for i, step := range result {
+		if _, ok := step.(int); !ok {
+			continue
+		}
+
+		if len(keys) == 0 || keys[0].field == "" {
+			return nil, false
+		}
+		key := keys[0]
+		keys = keys[1:]
+
+		parent, ok := getJSONPathValue(content, result[:i])
+		if !ok {
+			return nil, false
+		}
+		list, ok := parent.([]any)
+		if !ok {
+			return nil, false
+		}
+
+		index := -1
+		for j, element := range list {
+			if getListElementKey(element) != key {
+				continue
+			}
+			if index >= 0 {
+				return nil, false
+			}
+			index = j
+		}
+		if index < 0 {
+			return nil, false
+		}
+		result[i] = index
+	}
+
+	return result, true
+}
+
+// parseJSONFieldPath splits a field path in the format used by the strict
+// decoder, i.e. `spec.containers[0].name`, into map keys and list indexes.
+func parseJSONFieldPath(path string) []any {
+	var result []any
+	for _, field := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(field, "[")
+		if name != "" {
+			result = append(result, name)
+		}
+
+		for rest != "" {
+			var indexStr string
+			indexStr, rest, _ = strings.Cut(rest, "]")
+			rest = strings.TrimPrefix(rest, "[")
+			if index, err := strconv.Atoi(indexStr); err == nil {
+				result = append(result, index)
+			}
+		}
+	}
+
+	return result
+}
+
+func getJSONPathValue(content any, path []any) (any, bool) {
+	current := content
+	for _, step := range path {
+		switch step := step.(type) {
+		case string:
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[step]; !ok {
+				return nil, false
+			}
+
+		case int:
+			list, ok := current.([]any)
+			if !ok || step >= len(list) {
+				return nil, false
+			}
+			current = list[step]
+		}
+	}
+
+	return current, true
+}
+
+// setJSONPathValue sets the value at the passed path, reporting
+// whether its parent has been found.
+func setJSONPathValue(content any, path []any, value any) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	parent, ok := getJSONPathValue(content, path[:len(path)-1])
+	if !ok {
+		return false
+	}
+
+	switch step := path[len(path)-1].(type) {
+	case string:
+		if object, ok := parent.(map[string]any); ok {
+			object[step] = value
+			return true
+		}
+
+	case int:
+		if list, ok := parent.([]any); ok && step < len(list) {
+			list[step] = value
+			return true
+		}
+	}
+
+	return false
+}
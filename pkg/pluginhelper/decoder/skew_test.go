@@ -0,0 +1,205 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoder
+
+import (
+	"encoding/json"
+	"slices"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DecodeClusterSkewAware", func() {
+	newerGVK := schema.GroupVersionKind{
+		Group:   apiv1.SchemeGroupVersion.Group,
+		Version: "v2",
+		Kind:    apiv1.ClusterKind,
+	}
+
+	clusterJSON := []byte(`{
+		"apiVersion": "postgresql.cnpg.io/v2",
+		"kind": "Cluster",
+		"metadata": {"name": "cluster-example"},
+		"spec": {
+			"instances": 3,
+			"newField": {"enabled": true},
+			"plugins": [{"name": "test-plugin", "newPluginField": "value"}]
+		}
+	}`)
+
+	It("should refuse incompatible GVKs", func() {
+		_, err := DecodeClusterSkewAware(clusterJSON)
+		var wrongTypeErr *WrongObjectTypeError
+		Expect(err).To(BeAssignableToTypeOf(wrongTypeErr))
+	})
+
+	It("should record the received GVK and the unknown fields", func() {
+		result, err := DecodeClusterSkewAware(clusterJSON, getClusterGVK(), newerGVK)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.ReceivedGVK).To(Equal(newerGVK))
+		Expect(result.Object.Name).To(Equal("cluster-example"))
+		Expect(result.Object.Spec.Instances).To(Equal(3))
+		Expect(result.UnknownFields).To(HaveLen(2))
+		Expect(result.UnknownFields).To(HaveKeyWithValue("spec.newField", json.RawMessage(`{"enabled":true}`)))
+		Expect(result.UnknownFields).To(HaveKeyWithValue("spec.plugins[0].newPluginField", json.RawMessage(`"value"`)))
+	})
+
+	It("should restore the unknown fields when serializing the mutated object", func() {
+		result, err := DecodeClusterSkewAware(clusterJSON, getClusterGVK(), newerGVK)
+		Expect(err).ToNot(HaveOccurred())
+
+		result.Object.Spec.Instances = 5
+		data, err := result.Marshal()
+		Expect(err).ToNot(HaveOccurred())
+
+		var content map[string]any
+		Expect(json.Unmarshal(data, &content)).To(Succeed())
+		Expect(content).To(HaveKeyWithValue("apiVersion", "postgresql.cnpg.io/v2"))
+
+		spec := content["spec"].(map[string]any)
+		Expect(spec).To(HaveKeyWithValue("instances", BeNumerically("==", 5)))
+		Expect(spec).To(HaveKeyWithValue("newField", map[string]any{"enabled": true}))
+		Expect(spec["plugins"]).To(ConsistOf(map[string]any{
+			"name":           "test-plugin",
+			"newPluginField": "value",
+		}))
+	})
+
+	It("should drop the unknown fields whose parent has been removed", func() {
+		result, err := DecodeClusterSkewAware(clusterJSON, getClusterGVK(), newerGVK)
+		Expect(err).ToNot(HaveOccurred())
+
+		result.Object.Spec.Plugins = nil
+		data, err := result.Marshal()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).ToNot(ContainSubstring("newPluginField"))
+		Expect(string(data)).To(ContainSubstring("newField"))
+	})
+})
+
+var _ = Describe("SkewAwareObject list elements", func() {
+	clusterJSON := []byte(`{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind": "Cluster",
+		"metadata": {"name": "cluster-example"},
+		"spec": {
+			"instances": 3,
+			"plugins": [
+				{"name": "a", "newField": "value-a"},
+				{"name": "b", "newField": "value-b"}
+			]
+		}
+	}`)
+
+	decodeAndMarshal := func(mutate func(cluster *apiv1.Cluster)) (map[string]any, []string) {
+		result, err := DecodeClusterSkewAware(clusterJSON)
+		Expect(err).ToNot(HaveOccurred())
+
+		mutate(result.Object)
+		data, droppedFields, err := result.MarshalDetectingDroppedFields()
+		Expect(err).ToNot(HaveOccurred())
+
+		var content map[string]any
+		Expect(json.Unmarshal(data, &content)).To(Succeed())
+		return content, droppedFields
+	}
+
+	pluginsOf := func(content map[string]any) []any {
+		return content["spec"].(map[string]any)["plugins"].([]any)
+	}
+
+	It("should restore the unknown fields by merge key when an element is inserted", func() {
+		content, droppedFields := decodeAndMarshal(func(cluster *apiv1.Cluster) {
+			cluster.Spec.Plugins = append([]apiv1.PluginConfiguration{{Name: "mine"}}, cluster.Spec.Plugins...)
+		})
+
+		Expect(droppedFields).To(BeEmpty())
+		Expect(pluginsOf(content)).To(Equal([]any{
+			map[string]any{"name": "mine"},
+			map[string]any{"name": "a", "newField": "value-a"},
+			map[string]any{"name": "b", "newField": "value-b"},
+		}))
+	})
+
+	It("should restore the unknown fields by merge key when the elements are reordered", func() {
+		content, droppedFields := decodeAndMarshal(func(cluster *apiv1.Cluster) {
+			slices.Reverse(cluster.Spec.Plugins)
+		})
+
+		Expect(droppedFields).To(BeEmpty())
+		Expect(pluginsOf(content)).To(Equal([]any{
+			map[string]any{"name": "b", "newField": "value-b"},
+			map[string]any{"name": "a", "newField": "value-a"},
+		}))
+	})
+
+	It("should drop and report the unknown fields of removed elements", func() {
+		content, droppedFields := decodeAndMarshal(func(cluster *apiv1.Cluster) {
+			cluster.Spec.Plugins = cluster.Spec.Plugins[1:]
+		})
+
+		Expect(droppedFields).To(Equal([]string{"spec.plugins[0].newField"}))
+		Expect(pluginsOf(content)).To(Equal([]any{
+			map[string]any{"name": "b", "newField": "value-b"},
+		}))
+	})
+
+	It("should drop and report the unknown fields of elements without a merge key", func() {
+		result, err := DecodeClusterSkewAware([]byte(`{
+			"apiVersion": "postgresql.cnpg.io/v1",
+			"kind": "Cluster",
+			"metadata": {"name": "cluster-example"},
+			"spec": {"managed": {"services": {"additional": [{"newField": true, "selectorType": "rw"}]}}}
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		data, droppedFields, err := result.MarshalDetectingDroppedFields()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(droppedFields).To(Equal([]string{"spec.managed.services.additional[0].newField"}))
+		Expect(string(data)).ToNot(ContainSubstring("newField"))
+	})
+
+	It("should preserve large integers", func() {
+		result, err := DecodeClusterSkewAware([]byte(`{
+			"apiVersion": "postgresql.cnpg.io/v1",
+			"kind": "Cluster",
+			"metadata": {"name": "cluster-example"},
+			"spec": {"newField": 12345678901234567890}
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.UnknownFields).To(HaveKeyWithValue("spec.newField", json.RawMessage(`12345678901234567890`)))
+
+		data, err := result.Marshal()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"newField":12345678901234567890`))
+	})
+})
+
+var _ = Describe("parseJSONFieldPath", func() {
+	It("should split field names and list indexes", func() {
+		Expect(parseJSONFieldPath("spec.containers[0].env[12].name")).To(
+			Equal([]any{"spec", "containers", 0, "env", 12, "name"}))
+		Expect(parseJSONFieldPath("newField")).To(Equal([]any{"newField"}))
+	})
+})
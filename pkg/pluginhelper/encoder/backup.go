@@ -0,0 +1,27 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encoder
+
+import apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+
+// EncodeBackup encodes a backup into its JSON representation.
+func EncodeBackup(backup *apiv1.Backup, mode Mode) ([]byte, error) {
+	return Encode(backup, mode)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/decoder"
+)
+
+// ErrNilObject is raised when a nil object is passed to the encoder.
+var ErrNilObject = errors.New("cannot encode a nil object")
+
+// Mode controls which fields are encoded.
+type Mode int
+
+const (
+	// DefaultMode encodes every field of the object.
+	DefaultMode Mode = iota
+
+	// StripServerManagedFieldsMode encodes the object omitting the
+	// metadata fields managed by the API server, such as uid,
+	// resourceVersion, generation, creationTimestamp and managedFields.
+	// This is useful when the object is meant to be created.
+	StripServerManagedFieldsMode
+)
+
+// Encode encodes an object of type T into its JSON representation, i.e.
+//
+//	podJSON, err := encoder.Encode(pod, encoder.DefaultMode)
+//
+// The apiVersion and kind are set to the GVK registered in
+// decoder.Scheme for T, so that the result is accepted by the
+// strict decoders.
+func Encode[T any, PT decoder.ObjectPointer[T]](object PT, mode Mode) ([]byte, error) {
+	return EncodeWithScheme[T, PT](object, decoder.Scheme, mode)
+}
+
+// EncodeWithScheme is like Encode, using the passed scheme to derive
+// the GVK of the object.
+func EncodeWithScheme[T any, PT decoder.ObjectPointer[T]](
+	object PT,
+	scheme *runtime.Scheme,
+	mode Mode,
+) ([]byte, error) {
+	if object == nil {
+		return nil, ErrNilObject
+	}
+
+	gvks, _, err := scheme.ObjectKinds(object)
+	if err != nil {
+		return nil, fmt.Errorf("while detecting the GVK of the object: %w", err)
+	}
+
+	return EncodeObject(object, gvks[0], mode)
+}
+
+// EncodeObject encodes an object into its JSON representation, setting
+// its apiVersion and kind to the passed GVK. The passed object is
+// not modified.
+func EncodeObject(object runtime.Object, gvk schema.GroupVersionKind, mode Mode) ([]byte, error) {
+	if object == nil {
+		return nil, ErrNilObject
+	}
+
+	object = object.DeepCopyObject()
+	object.GetObjectKind().SetGroupVersionKind(gvk)
+
+	if mode == StripServerManagedFieldsMode {
+		if err := stripServerManagedFields(object); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := json.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling object JSON: %w", err)
+	}
+
+	return result, nil
+}
+
+func stripServerManagedFields(object runtime.Object) error {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return fmt.Errorf("while accessing the object metadata: %w", err)
+	}
+
+	accessor.SetUID("")
+	accessor.SetResourceVersion("")
+	accessor.SetGeneration(0)
+	accessor.SetCreationTimestamp(metav1.Time{})
+	accessor.SetDeletionTimestamp(nil)
+	accessor.SetDeletionGracePeriodSeconds(nil)
+	accessor.SetManagedFields(nil)
+	accessor.SetSelfLink("")
+
+	return nil
+}
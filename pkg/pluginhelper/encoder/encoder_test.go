@@ -0,0 +1,156 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encoder
+
+import (
+	"encoding/json"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/decoder"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newServerManagedMetadata(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         "default",
+		Labels:            map[string]string{"app": "test"},
+		UID:               "uid",
+		ResourceVersion:   "42",
+		Generation:        3,
+		CreationTimestamp: metav1.Now(),
+		ManagedFields: []metav1.ManagedFieldsEntry{
+			{Manager: "cnpg", Operation: metav1.ManagedFieldsOperationApply},
+		},
+	}
+}
+
+var _ = Describe("Encoder", func() {
+	It("should round-trip Clusters with the strict decoder", func() {
+		cluster := &apiv1.Cluster{
+			ObjectMeta: newServerManagedMetadata("cluster"),
+			Spec: apiv1.ClusterSpec{
+				Instances: 3,
+				Plugins: []apiv1.PluginConfiguration{
+					{Name: "plugin.cnpg.io", Parameters: map[string]string{"a": "b"}},
+				},
+			},
+		}
+
+		clusterJSON, err := EncodeCluster(cluster, DefaultMode)
+		Expect(err).ToNot(HaveOccurred())
+
+		decoded, err := decoder.DecodeClusterStrict(clusterJSON)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded.Spec).To(Equal(cluster.Spec))
+		Expect(decoded.ResourceVersion).To(Equal("42"))
+		Expect(decoded.Kind).To(Equal(apiv1.ClusterKind))
+	})
+
+	It("should round-trip Backups with the strict decoder", func() {
+		backup := &apiv1.Backup{
+			ObjectMeta: newServerManagedMetadata("backup"),
+			Spec: apiv1.BackupSpec{
+				Cluster: apiv1.LocalObjectReference{Name: "cluster"},
+				Method:  apiv1.BackupMethodPlugin,
+			},
+		}
+
+		backupJSON, err := EncodeBackup(backup, DefaultMode)
+		Expect(err).ToNot(HaveOccurred())
+
+		decoded, err := decoder.DecodeBackupStrict(backupJSON)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded.Spec).To(Equal(backup.Spec))
+	})
+
+	It("should round-trip Pods with the strict decoder", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: newServerManagedMetadata("pod"),
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "postgres", Image: "postgres:17"}},
+			},
+		}
+
+		podJSON, err := EncodePod(pod, DefaultMode)
+		Expect(err).ToNot(HaveOccurred())
+
+		decoded, err := decoder.DecodePodJSON(podJSON)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded.Spec).To(Equal(pod.Spec))
+		Expect(decoded.APIVersion).To(Equal("v1"))
+	})
+
+	It("should not modify the passed object", func() {
+		pod := &corev1.Pod{ObjectMeta: newServerManagedMetadata("pod")}
+		expected := pod.DeepCopy()
+
+		_, err := EncodePod(pod, StripServerManagedFieldsMode)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod).To(Equal(expected))
+	})
+
+	It("should overwrite a wrong type metadata", func() {
+		pod := &corev1.Pod{TypeMeta: metav1.TypeMeta{APIVersion: "v2", Kind: "Service"}}
+
+		podJSON, err := EncodePod(pod, DefaultMode)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = decoder.DecodePodJSON(podJSON)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should strip the server-managed fields on request", func() {
+		pod := &corev1.Pod{ObjectMeta: newServerManagedMetadata("pod")}
+		pod.DeletionTimestamp = ptr.To(metav1.Now())
+		pod.DeletionGracePeriodSeconds = ptr.To(int64(30))
+
+		podJSON, err := EncodePod(pod, StripServerManagedFieldsMode)
+		Expect(err).ToNot(HaveOccurred())
+
+		var content map[string]any
+		Expect(json.Unmarshal(podJSON, &content)).To(Succeed())
+		Expect(content["metadata"]).To(Equal(map[string]any{
+			"name":      "pod",
+			"namespace": "default",
+			"labels":    map[string]any{"app": "test"},
+		}))
+	})
+
+	It("should fail for nil objects", func() {
+		_, err := EncodeCluster(nil, DefaultMode)
+		Expect(err).To(MatchError(ErrNilObject))
+
+		_, err = EncodeObject(nil, schema.GroupVersionKind{}, DefaultMode)
+		Expect(err).To(MatchError(ErrNilObject))
+	})
+
+	It("should fail for types unknown to the scheme", func() {
+		_, err := EncodeWithScheme(&corev1.Pod{}, runtime.NewScheme(), DefaultMode)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,117 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatchv5 "github.com/evanphx/json-patch/v5"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// ApplyPatch applies an RFC 6902 JSON patch, such as the ones created
+// by CreatePatch, to the original object and returns the patched object.
+// The original object is not modified. This is meant to be used when
+// testing the patches generated by the lifecycle hooks, i.e.
+//
+//	patchedPod, err := object.ApplyPatch(pod, patch)
+func ApplyPatch[T any, PT interface {
+	*T
+	runtime.Object
+}](original PT, patch []byte) (PT, error) {
+	return ApplyPatchOfType(types.JSONPatchType, original, patch)
+}
+
+// ApplyPatchOfType applies a patch of the passed type to the original
+// object and returns the patched object. The supported patch types are
+// the ones supported by CreatePatchOfType. An empty patch leaves
+// the object unchanged.
+func ApplyPatchOfType[T any, PT interface {
+	*T
+	runtime.Object
+}](patchType types.PatchType, original PT, patch []byte) (PT, error) {
+	if original == nil {
+		return nil, ErrNilObject
+	}
+
+	if len(patch) == 0 {
+		return original.DeepCopyObject().(PT), nil
+	}
+
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return nil, fmt.Errorf("while marshalling the original object: %w", err)
+	}
+
+	patchedJSON, err := applyPatchToJSON(patchType, originalJSON, patch, original)
+	if err != nil {
+		return nil, err
+	}
+
+	result := PT(new(T))
+	if err := json.Unmarshal(patchedJSON, result); err != nil {
+		return nil, fmt.Errorf("while unmarshalling the patched object: %w", err)
+	}
+
+	return result, nil
+}
+
+func applyPatchToJSON(
+	patchType types.PatchType,
+	originalJSON, patch []byte,
+	dataStruct runtime.Object,
+) ([]byte, error) {
+	switch patchType {
+	case types.JSONPatchType:
+		decodedPatch, err := jsonpatchv5.DecodePatch(patch)
+		if err != nil {
+			return nil, fmt.Errorf("while decoding JSON patch: %w", err)
+		}
+
+		result, err := decodedPatch.Apply(originalJSON)
+		if err != nil {
+			return nil, fmt.Errorf("while applying JSON patch: %w", err)
+		}
+
+		return result, nil
+
+	case types.MergePatchType:
+		result, err := jsonpatchv5.MergePatch(originalJSON, patch)
+		if err != nil {
+			return nil, fmt.Errorf("while applying JSON merge patch: %w", err)
+		}
+
+		return result, nil
+
+	case types.StrategicMergePatchType:
+		result, err := strategicpatch.StrategicMergePatch(originalJSON, patch, dataStruct)
+		if err != nil {
+			return nil, fmt.Errorf("while applying strategic merge patch: %w", err)
+		}
+
+		return result, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPatchType, patchType)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ApplyPatch", func() {
+	It("should apply the patches created by CreatePatch", func() {
+		pod := newTestPod()
+		updatedPod := pod.DeepCopy()
+		updatedPod.Spec.Containers[0].Image = "postgres:17"
+
+		patch, err := CreatePatch(updatedPod, pod)
+		Expect(err).ToNot(HaveOccurred())
+
+		patchedPod, err := ApplyPatch(pod, patch)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patchedPod).To(Equal(updatedPod))
+		Expect(pod.Spec.Containers[0].Image).To(Equal("postgres:16"))
+	})
+
+	It("should return a copy of the object for empty patches", func() {
+		pod := newTestPod()
+
+		patchedPod, err := ApplyPatch(pod, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patchedPod).To(Equal(pod))
+		Expect(patchedPod).ToNot(BeIdenticalTo(pod))
+	})
+
+	It("should apply strategic merge patches", func() {
+		patchedPod, err := ApplyPatchOfType(
+			types.StrategicMergePatchType,
+			newTestPod(),
+			[]byte(`{"spec":{"containers":[{"name":"sidecar","image":"sidecar:2"}]}}`),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patchedPod.Spec.Containers).To(HaveLen(2))
+		Expect(patchedPod.Spec.Containers[1].Image).To(Equal("sidecar:2"))
+	})
+
+	It("should apply merge patches", func() {
+		patchedPod, err := ApplyPatchOfType(
+			types.MergePatchType,
+			newTestPod(),
+			[]byte(`{"metadata":{"labels":{"app":null}}}`),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patchedPod.Labels).To(BeEmpty())
+	})
+
+	It("should fail for invalid patches", func() {
+		_, err := ApplyPatch(newTestPod(), []byte(`[{"op":"remove","path":"/spec/volumes/3"}]`))
+		Expect(err).To(HaveOccurred())
+
+		_, err = ApplyPatch(newTestPod(), []byte(`{}`))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail for nil objects", func() {
+		_, err := ApplyPatch[corev1.Pod](nil, []byte(`[]`))
+		Expect(err).To(MatchError(ErrNilObject))
+	})
+
+	It("should fail for unsupported patch types", func() {
+		_, err := ApplyPatchOfType(types.ApplyYAMLPatchType, newTestPod(), []byte(`{}`))
+		Expect(err).To(MatchError(ErrUnsupportedPatchType))
+	})
+})
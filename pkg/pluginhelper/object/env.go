@@ -0,0 +1,260 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+var (
+	// ErrContainerNotFound is raised when the container to be changed
+	// is not in the passed Pod.
+	ErrContainerNotFound = errors.New("container not found")
+
+	// ErrEnvConflict is raised when injecting an environment variable
+	// or source conflicting with an existing one using the
+	// FailOnConflict policy.
+	ErrEnvConflict = errors.New("conflicting environment definition")
+)
+
+// ConflictPolicy is what to do when injecting an environment variable,
+// or source, conflicting with an existing one.
+type ConflictPolicy int
+
+const (
+	// KeepExisting leaves the existing environment variable untouched.
+	KeepExisting ConflictPolicy = iota
+
+	// Overwrite replaces the existing environment variable, keeping
+	// its position.
+	Overwrite
+
+	// FailOnConflict makes the injection fail with ErrEnvConflict,
+	// leaving the container untouched.
+	FailOnConflict
+)
+
+// InjectEnv refer to InjectEnvSpec.
+func InjectEnv(pod *corev1.Pod, containerName string, policy ConflictPolicy, envVars ...corev1.EnvVar) error {
+	if pod == nil {
+		return ErrNilPodPassed
+	}
+
+	return InjectEnvSpec(&pod.Spec, containerName, policy, envVars...)
+}
+
+// InjectEnvSpec injects the passed environment variables into the
+// container, or init container, having the passed name.
+//
+// Environment variables already having the same definition are left
+// untouched, so that the injection is idempotent, while the ones
+// conflicting with the existing definition are handled according
+// to the passed policy.
+func InjectEnvSpec(
+	spec *corev1.PodSpec,
+	containerName string,
+	policy ConflictPolicy,
+	envVars ...corev1.EnvVar,
+) error {
+	if spec == nil {
+		return nil
+	}
+
+	container, err := findContainer(spec, containerName)
+	if err != nil {
+		return err
+	}
+
+	keyFunc := func(envVar corev1.EnvVar) string {
+		return envVar.Name
+	}
+
+	return injectUniqueItems(&container.Env, containerName, policy, keyFunc, envVars)
+}
+
+// RemoveEnv refer to RemoveEnvSpec.
+func RemoveEnv(pod *corev1.Pod, containerName string, names ...string) error {
+	if pod == nil {
+		return ErrNilPodPassed
+	}
+
+	return RemoveEnvSpec(&pod.Spec, containerName, names...)
+}
+
+// RemoveEnvSpec removes the environment variables having the passed
+// names from the container, or init container, having the passed name.
+// Missing environment variables are ignored.
+func RemoveEnvSpec(spec *corev1.PodSpec, containerName string, names ...string) error {
+	if spec == nil {
+		return nil
+	}
+
+	container, err := findContainer(spec, containerName)
+	if err != nil {
+		return err
+	}
+
+	container.Env = slices.DeleteFunc(container.Env, func(envVar corev1.EnvVar) bool {
+		return slices.Contains(names, envVar.Name)
+	})
+
+	return nil
+}
+
+// InjectEnvFrom refer to InjectEnvFromSpec.
+func InjectEnvFrom(
+	pod *corev1.Pod,
+	containerName string,
+	policy ConflictPolicy,
+	sources ...corev1.EnvFromSource,
+) error {
+	if pod == nil {
+		return ErrNilPodPassed
+	}
+
+	return InjectEnvFromSpec(&pod.Spec, containerName, policy, sources...)
+}
+
+// InjectEnvFromSpec injects the passed environment sources into the
+// container, or init container, having the passed name.
+//
+// Sources are identified by the ConfigMap or Secret they refer to and
+// by their prefix. Sources already having the same definition are left
+// untouched, so that the injection is idempotent, while the ones
+// conflicting with the existing definition, i.e. having a different
+// "optional" flag, are handled according to the passed policy.
+func InjectEnvFromSpec(
+	spec *corev1.PodSpec,
+	containerName string,
+	policy ConflictPolicy,
+	sources ...corev1.EnvFromSource,
+) error {
+	if spec == nil {
+		return nil
+	}
+
+	container, err := findContainer(spec, containerName)
+	if err != nil {
+		return err
+	}
+
+	return injectUniqueItems(&container.EnvFrom, containerName, policy, getEnvFromSourceKey, sources)
+}
+
+// RemoveEnvFrom refer to RemoveEnvFromSpec.
+func RemoveEnvFrom(pod *corev1.Pod, containerName string, sources ...corev1.EnvFromSource) error {
+	if pod == nil {
+		return ErrNilPodPassed
+	}
+
+	return RemoveEnvFromSpec(&pod.Spec, containerName, sources...)
+}
+
+// RemoveEnvFromSpec removes the passed environment sources from the
+// container, or init container, having the passed name. Sources are
+// identified like in InjectEnvFromSpec, and missing ones are ignored.
+func RemoveEnvFromSpec(spec *corev1.PodSpec, containerName string, sources ...corev1.EnvFromSource) error {
+	if spec == nil {
+		return nil
+	}
+
+	container, err := findContainer(spec, containerName)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, len(sources))
+	for i := range sources {
+		keys[i] = getEnvFromSourceKey(sources[i])
+	}
+
+	container.EnvFrom = slices.DeleteFunc(container.EnvFrom, func(source corev1.EnvFromSource) bool {
+		return slices.Contains(keys, getEnvFromSourceKey(source))
+	})
+
+	return nil
+}
+
+// injectUniqueItems injects items identified by a key into a list,
+// handling the conflicts according to the passed policy. The list is
+// only changed when no conflict makes the injection fail.
+func injectUniqueItems[T any](
+	target *[]T,
+	containerName string,
+	policy ConflictPolicy,
+	keyFunc func(T) string,
+	items []T,
+) error {
+	result := slices.Clone(*target)
+	for _, item := range items {
+		idx := slices.IndexFunc(result, func(existingItem T) bool {
+			return keyFunc(existingItem) == keyFunc(item)
+		})
+
+		switch {
+		case idx < 0:
+			result = append(result, item)
+
+		case equality.Semantic.DeepEqual(result[idx], item):
+			continue
+
+		case policy == Overwrite:
+			result[idx] = item
+
+		case policy == FailOnConflict:
+			return fmt.Errorf("%w: %s in container %s", ErrEnvConflict, keyFunc(item), containerName)
+		}
+	}
+
+	*target = result
+	return nil
+}
+
+func getEnvFromSourceKey(source corev1.EnvFromSource) string {
+	switch {
+	case source.ConfigMapRef != nil:
+		return fmt.Sprintf("configmap/%s/%s", source.ConfigMapRef.Name, source.Prefix)
+	case source.SecretRef != nil:
+		return fmt.Sprintf("secret/%s/%s", source.SecretRef.Name, source.Prefix)
+	default:
+		return "/" + source.Prefix
+	}
+}
+
+func findContainer(spec *corev1.PodSpec, containerName string) (*corev1.Container, error) {
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == containerName {
+			return &spec.Containers[i], nil
+		}
+	}
+
+	for i := range spec.InitContainers {
+		if spec.InitContainers[i].Name == containerName {
+			return &spec.InitContainers[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrContainerNotFound, containerName)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Environment injection", func() {
+	var pod *corev1.Pod
+
+	BeforeEach(func() {
+		pod = &corev1.Pod{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "bootstrap"}},
+				Containers: []corev1.Container{
+					{
+						Name: PostgresContainerName,
+						Env: []corev1.EnvVar{
+							{Name: "A", Value: "1"},
+							{Name: "B", Value: "2"},
+						},
+					},
+					{Name: "sidecar"},
+				},
+			},
+		}
+	})
+
+	It("should be idempotent across repeated invocations", func() {
+		envVars := []corev1.EnvVar{{Name: "C", Value: "3"}, {Name: "B", Value: "2"}}
+		for range 3 {
+			Expect(InjectEnv(pod, PostgresContainerName, FailOnConflict, envVars...)).To(Succeed())
+		}
+
+		Expect(pod.Spec.Containers[0].Env).To(Equal([]corev1.EnvVar{
+			{Name: "A", Value: "1"},
+			{Name: "B", Value: "2"},
+			{Name: "C", Value: "3"},
+		}))
+	})
+
+	DescribeTable(
+		"should handle conflicts according to the policy",
+		func(policy ConflictPolicy, expectedValue string, succeeds bool) {
+			err := InjectEnv(pod, PostgresContainerName, policy,
+				corev1.EnvVar{Name: "D", Value: "4"},
+				corev1.EnvVar{Name: "A", Value: "changed"},
+			)
+			if !succeeds {
+				Expect(err).To(MatchError(ErrEnvConflict))
+				Expect(pod.Spec.Containers[0].Env).To(HaveLen(2))
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(pod.Spec.Containers[0].Env).To(HaveLen(3))
+			}
+			Expect(pod.Spec.Containers[0].Env[0]).To(Equal(corev1.EnvVar{Name: "A", Value: expectedValue}))
+		},
+		Entry("keeping the existing value", KeepExisting, "1", true),
+		Entry("overwriting the existing value", Overwrite, "changed", true),
+		Entry("failing", FailOnConflict, "1", false),
+	)
+
+	It("should inject into sidecars and init containers", func() {
+		envVar := corev1.EnvVar{Name: "A", Value: "1"}
+		Expect(InjectEnv(pod, "sidecar", FailOnConflict, envVar)).To(Succeed())
+		Expect(InjectEnv(pod, "bootstrap", FailOnConflict, envVar)).To(Succeed())
+
+		Expect(pod.Spec.Containers[1].Env).To(ConsistOf(envVar))
+		Expect(pod.Spec.InitContainers[0].Env).To(ConsistOf(envVar))
+	})
+
+	It("should remove environment variables", func() {
+		for range 2 {
+			Expect(RemoveEnv(pod, PostgresContainerName, "A", "missing")).To(Succeed())
+		}
+		Expect(pod.Spec.Containers[0].Env).To(Equal([]corev1.EnvVar{{Name: "B", Value: "2"}}))
+	})
+
+	It("should inject and remove environment sources", func() {
+		configMapSource := corev1.EnvFromSource{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}},
+		}
+		secretSource := corev1.EnvFromSource{
+			Prefix:    "PLUGIN_",
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "secret"}},
+		}
+
+		for range 2 {
+			Expect(InjectEnvFrom(pod, "sidecar", FailOnConflict, configMapSource, secretSource)).To(Succeed())
+		}
+		Expect(pod.Spec.Containers[1].EnvFrom).To(Equal([]corev1.EnvFromSource{configMapSource, secretSource}))
+
+		optionalSecretSource := *secretSource.DeepCopy()
+		optionalSecretSource.SecretRef.Optional = ptr.To(true)
+		Expect(InjectEnvFrom(pod, "sidecar", FailOnConflict, optionalSecretSource)).To(MatchError(ErrEnvConflict))
+		Expect(InjectEnvFrom(pod, "sidecar", Overwrite, optionalSecretSource)).To(Succeed())
+		Expect(pod.Spec.Containers[1].EnvFrom[1]).To(Equal(optionalSecretSource))
+
+		Expect(RemoveEnvFrom(pod, "sidecar", configMapSource)).To(Succeed())
+		Expect(pod.Spec.Containers[1].EnvFrom).To(Equal([]corev1.EnvFromSource{optionalSecretSource}))
+	})
+
+	It("should fail for missing containers", func() {
+		envVar := corev1.EnvVar{Name: "A", Value: "1"}
+		Expect(InjectEnv(pod, "missing", FailOnConflict, envVar)).To(MatchError(ErrContainerNotFound))
+		Expect(RemoveEnv(pod, "missing", "A")).To(MatchError(ErrContainerNotFound))
+		Expect(InjectEnvFrom(pod, "missing", FailOnConflict)).To(MatchError(ErrContainerNotFound))
+		Expect(RemoveEnvFrom(pod, "missing")).To(MatchError(ErrContainerNotFound))
+	})
+
+	It("should fail for nil pods", func() {
+		Expect(InjectEnv(nil, PostgresContainerName, FailOnConflict)).To(MatchError(ErrNilPodPassed))
+		Expect(RemoveEnv(nil, PostgresContainerName)).To(MatchError(ErrNilPodPassed))
+		Expect(InjectEnvFrom(nil, PostgresContainerName, FailOnConflict)).To(MatchError(ErrNilPodPassed))
+		Expect(RemoveEnvFrom(nil, PostgresContainerName)).To(MatchError(ErrNilPodPassed))
+	})
+})
@@ -0,0 +1,221 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	jsonpatchv5 "github.com/evanphx/json-patch/v5"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ErrUnsafePatchDrop is raised when dropping the operations touching
+// disallowed paths would change the meaning of the allowed ones.
+var ErrUnsafePatchDrop = errors.New("dropping the disallowed operations would change the meaning of the patch")
+
+// PatchGuardPolicy is what a PatchGuard does with the operations
+// touching disallowed paths.
+type PatchGuardPolicy int
+
+const (
+	// RejectDisallowedPaths makes the PatchGuard fail with a
+	// DisallowedPatchPathsError.
+	RejectDisallowedPaths PatchGuardPolicy = iota
+
+	// DropDisallowedPaths makes the PatchGuard remove the operations
+	// from the patch.
+	//
+	// Operations address list elements by index, and the ones created
+	// by CreatePatch change an element into another one instead of
+	// removing it. Dropping an operation which changes a list element,
+	// or the list itself, may make the remaining operations touch a
+	// different element than the intended one. In this case the
+	// PatchGuard fails with ErrUnsafePatchDrop instead.
+	DropDisallowedPaths
+)
+
+// PatchGuard restricts the JSON patches created by a mutating hook
+// to the paths it declares to change, i.e.
+//
+//	var clusterPatchGuard = object.PatchGuard{
+//		AllowedPaths: []string{"/spec/plugins", "/metadata/annotations"},
+//	}
+//
+//	patch, err := clusterPatchGuard.CreatePatch(mutatedCluster, cluster)
+//
+// Paths are matched like in CheckPatchPaths. An operation replacing
+// a parent of an allowed path, i.e. adding the whole
+// "/metadata/annotations" map when only "/metadata/annotations/a"
+// is allowed, touches a disallowed path.
+type PatchGuard struct {
+	// AllowedPaths are the JSON pointers of the paths, together
+	// with their children, the patch can change
+	AllowedPaths []string
+
+	// Policy is what to do with the operations touching
+	// disallowed paths
+	Policy PatchGuardPolicy
+}
+
+// CreatePatch creates a JSON patch from the diff between the old and new
+// object like CreatePatch, enforcing the guard on the result.
+func (g PatchGuard) CreatePatch(newObject, oldObject runtime.Object) ([]byte, error) {
+	patch, err := CreatePatch(newObject, oldObject)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.Enforce(patch)
+}
+
+// Enforce enforces the guard on an existing JSON patch, returning
+// the patch to be used.
+func (g PatchGuard) Enforce(patch []byte) ([]byte, error) {
+	allowedOperations, disallowedOperations, disallowedPaths, err := splitPatchOperations(patch, g.AllowedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(disallowedPaths) == 0 {
+		return patch, nil
+	}
+
+	if g.Policy == RejectDisallowedPaths {
+		return nil, &DisallowedPatchPathsError{Paths: disallowedPaths}
+	}
+
+	if len(allowedOperations) == 0 {
+		return []byte{}, nil
+	}
+
+	if unsafePaths := findUnsafeDroppedPaths(disallowedOperations, allowedOperations); len(unsafePaths) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsafePatchDrop, strings.Join(unsafePaths, ", "))
+	}
+
+	result, err := json.Marshal(allowedOperations)
+	if err != nil {
+		return nil, fmt.Errorf("while marshalling the filtered JSON patch: %w", err)
+	}
+
+	return result, nil
+}
+
+// findUnsafeDroppedPaths finds the paths of the dropped operations
+// which change a list element, or a list, some of the kept operations
+// go through.
+func findUnsafeDroppedPaths(droppedOperations, keptOperations jsonpatchv5.Patch) []string {
+	var keptElements [][]string
+	for _, operation := range keptOperations {
+		for _, path := range getOperationPaths(operation) {
+			keptElements = append(keptElements, getListElementPointers(path)...)
+		}
+	}
+
+	var result []string
+	for _, operation := range droppedOperations {
+		if operation.Kind() == "test" {
+			continue
+		}
+
+		for _, path := range getOperationPaths(operation) {
+			if !changesKeptElements(operation.Kind(), path, keptElements) {
+				continue
+			}
+
+			if !slices.Contains(result, path) {
+				result = append(result, path)
+			}
+		}
+	}
+
+	return result
+}
+
+// changesKeptElements checks if an operation at the passed path changes
+// any of the passed list elements, or the list containing them.
+func changesKeptElements(kind string, path string, keptElements [][]string) bool {
+	segments := strings.Split(path, "/")
+	changesList := kind != "replace" && isListIndexSegment(segments[len(segments)-1])
+
+	for _, element := range keptElements {
+		if len(segments) >= len(element) && slices.Equal(segments[:len(element)], element) {
+			return true
+		}
+
+		list := element[:len(element)-1]
+		if changesList && slices.Equal(segments[:len(segments)-1], list) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getOperationPaths gets the path of an operation and,
+// for move and copy, the one it reads from.
+func getOperationPaths(operation jsonpatchv5.Operation) []string {
+	var result []string
+	if path, err := operation.Path(); err == nil {
+		result = append(result, path)
+	}
+	if from, err := operation.From(); err == nil {
+		result = append(result, from)
+	}
+
+	return result
+}
+
+// getListElementPointers gets the segments of the pointers to the list
+// elements the passed path goes through.
+func getListElementPointers(path string) [][]string {
+	segments := strings.Split(path, "/")
+
+	var result [][]string
+	for i := 1; i < len(segments); i++ {
+		if isListIndexSegment(segments[i]) {
+			result = append(result, segments[:i+1])
+		}
+	}
+
+	return result
+}
+
+// isListIndexSegment checks if a pointer segment may be a list index.
+func isListIndexSegment(segment string) bool {
+	if segment == "-" {
+		return true
+	}
+
+	if segment == "" {
+		return false
+	}
+
+	for _, c := range segment {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,152 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PatchGuard", func() {
+	var (
+		cluster        *apiv1.Cluster
+		mutatedCluster *apiv1.Cluster
+	)
+
+	BeforeEach(func() {
+		cluster = &apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				Instances: 1,
+				Plugins:   []apiv1.PluginConfiguration{{Name: "plugin.cnpg.io"}},
+			},
+		}
+		mutatedCluster = cluster.DeepCopy()
+		mutatedCluster.Spec.Plugins[0].Parameters = map[string]string{"a": "b"}
+		mutatedCluster.Spec.Instances = 3
+	})
+
+	It("should reject patches touching disallowed paths", func() {
+		guard := PatchGuard{AllowedPaths: []string{"/spec/plugins"}}
+
+		_, err := guard.CreatePatch(mutatedCluster, cluster)
+		Expect(err).To(MatchError(&DisallowedPatchPathsError{Paths: []string{"/spec/instances"}}))
+	})
+
+	It("should drop the operations touching disallowed paths", func() {
+		guard := PatchGuard{
+			AllowedPaths: []string{"/spec/plugins/*/parameters"},
+			Policy:       DropDisallowedPaths,
+		}
+
+		patch, err := guard.CreatePatch(mutatedCluster, cluster)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(patch)).To(MatchJSON(
+			`[{"op":"add","path":"/spec/plugins/0/parameters","value":{"a":"b"}}]`))
+
+		patchedCluster, err := ApplyPatch(cluster, patch)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patchedCluster.Spec.Instances).To(Equal(1))
+		Expect(patchedCluster.Spec.Plugins[0].Parameters).To(HaveKeyWithValue("a", "b"))
+	})
+
+	It("should refuse to drop operations changing the list elements of the kept ones", func() {
+		pod := &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "a", Env: []corev1.EnvVar{{Name: "VAR", Value: "a"}}},
+					{Name: "b", Env: []corev1.EnvVar{{Name: "VAR", Value: "b"}}},
+				},
+			},
+		}
+		mutatedPod := pod.DeepCopy()
+		mutatedPod.Spec.Containers = mutatedPod.Spec.Containers[1:]
+		mutatedPod.Spec.Containers[0].Env[0].Value = "b2"
+
+		guard := PatchGuard{
+			AllowedPaths: []string{"/spec/containers/*/env"},
+			Policy:       DropDisallowedPaths,
+		}
+
+		_, err := guard.CreatePatch(mutatedPod, pod)
+		Expect(err).To(MatchError(ErrUnsafePatchDrop))
+	})
+
+	It("should refuse to drop the removal of a list element before the kept ones", func() {
+		patch := []byte(`[
+			{"op":"remove","path":"/spec/containers/0"},
+			{"op":"replace","path":"/spec/containers/0/env/0/value","value":"b2"}
+		]`)
+
+		guard := PatchGuard{
+			AllowedPaths: []string{"/spec/containers/*/env"},
+			Policy:       DropDisallowedPaths,
+		}
+
+		_, err := guard.Enforce(patch)
+		Expect(err).To(MatchError(ErrUnsafePatchDrop))
+	})
+
+	It("should drop operations on unrelated list elements", func() {
+		patch := []byte(`[
+			{"op":"replace","path":"/spec/containers/1/image","value":"b:2"},
+			{"op":"replace","path":"/spec/containers/0/env/0/value","value":"a2"}
+		]`)
+
+		guard := PatchGuard{
+			AllowedPaths: []string{"/spec/containers/*/env"},
+			Policy:       DropDisallowedPaths,
+		}
+
+		result, err := guard.Enforce(patch)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).To(MatchJSON(
+			`[{"op":"replace","path":"/spec/containers/0/env/0/value","value":"a2"}]`))
+	})
+
+	It("should return an empty patch when every operation is dropped", func() {
+		guard := PatchGuard{
+			AllowedPaths: []string{"/metadata"},
+			Policy:       DropDisallowedPaths,
+		}
+
+		patch, err := guard.CreatePatch(mutatedCluster, cluster)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patch).To(BeEmpty())
+	})
+
+	It("should leave the allowed patches unchanged", func() {
+		guard := PatchGuard{AllowedPaths: []string{"/spec"}}
+
+		expected, err := CreatePatch(mutatedCluster, cluster)
+		Expect(err).ToNot(HaveOccurred())
+
+		patch, err := guard.CreatePatch(mutatedCluster, cluster)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patch).To(Equal(expected))
+	})
+
+	It("should propagate the errors creating the patch", func() {
+		_, err := PatchGuard{}.CreatePatch(nil, &corev1.Pod{})
+		Expect(err).To(HaveOccurred())
+	})
+})
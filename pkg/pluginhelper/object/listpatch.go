@@ -0,0 +1,449 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ListMergeKey declares the field identifying the elements of a list
+type ListMergeKey struct {
+	// Path is the JSON pointer of the list, where a "*" segment
+	// matches any single segment, i.e. "/spec/containers/*/env"
+	Path string
+
+	// Key is the name of the field identifying the list elements
+	Key string
+}
+
+// DefaultListMergeKeys are the merge keys of the lists of Pods and
+// Clusters that are usually changed by plugins
+var DefaultListMergeKeys = []ListMergeKey{
+	{Path: "/spec/containers", Key: "name"},
+	{Path: "/spec/initContainers", Key: "name"},
+	{Path: "/spec/volumes", Key: "name"},
+	{Path: "/spec/containers/*/env", Key: "name"},
+	{Path: "/spec/initContainers/*/env", Key: "name"},
+	{Path: "/spec/containers/*/volumeMounts", Key: "mountPath"},
+	{Path: "/spec/initContainers/*/volumeMounts", Key: "mountPath"},
+	{Path: "/spec/env", Key: "name"},
+	{Path: "/spec/plugins", Key: "name"},
+	{Path: "/spec/externalClusters", Key: "name"},
+	{Path: "/spec/managed/roles", Key: "name"},
+}
+
+// ListAwarePatcher creates RFC 6902 JSON patches diffing the lists
+// having a merge key by the key of their elements rather than by
+// their position. Inserting an element in such a list results in a
+// single "add" operation, instead of a cascade of "replace" ones
+// conflicting with the patches created by other plugins.
+//
+// Unless disabled, every operation changing an element of such a list
+// is preceded by a "test" operation checking the key of the element
+// at that position, so that the patch fails instead of changing the
+// wrong element when the list has been concurrently modified.
+type ListAwarePatcher struct {
+	// MergeKeys are the merge keys of the lists. When nil,
+	// DefaultListMergeKeys are used
+	MergeKeys []ListMergeKey
+
+	// SkipTestOperations disables the "test" operations
+	SkipTestOperations bool
+}
+
+// CreateListAwarePatch creates a JSON patch from the diff between the
+// old and new object using a ListAwarePatcher with the default settings.
+func CreateListAwarePatch(newObject, oldObject runtime.Object) ([]byte, error) {
+	return ListAwarePatcher{}.CreatePatch(newObject, oldObject)
+}
+
+// CreatePatch creates a JSON patch from the diff between the old and new
+// object. An empty patch is returned when the objects are equal.
+func (p ListAwarePatcher) CreatePatch(newObject, oldObject runtime.Object) ([]byte, error) {
+	newJSON, oldJSON, err := marshalObjects(newObject, oldObject)
+	if err != nil {
+		return nil, err
+	}
+
+	newContent, err := unmarshalContent(newJSON)
+	if err != nil {
+		return nil, fmt.Errorf("while unmarshalling the new object: %w", err)
+	}
+
+	oldContent, err := unmarshalContent(oldJSON)
+	if err != nil {
+		return nil, fmt.Errorf("while unmarshalling the old object: %w", err)
+	}
+
+	mergeKeys := p.MergeKeys
+	if mergeKeys == nil {
+		mergeKeys = DefaultListMergeKeys
+	}
+
+	d := listAwareDiffer{
+		mergeKeys:      mergeKeys,
+		testOperations: !p.SkipTestOperations,
+	}
+	operations, err := d.diff(nil, oldContent, newContent)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(operations) == 0 {
+		return []byte{}, nil
+	}
+
+	result, err := json.Marshal(operations)
+	if err != nil {
+		return nil, fmt.Errorf("while marshalling JSON patch: %w", err)
+	}
+
+	return result, nil
+}
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	From  string          `json:"from,omitempty"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type listAwareDiffer struct {
+	mergeKeys      []ListMergeKey
+	testOperations bool
+}
+
+func (d listAwareDiffer) diff(pointer []string, oldValue, newValue any) ([]jsonPatchOperation, error) {
+	switch newTypedValue := newValue.(type) {
+	case map[string]any:
+		if oldTypedValue, ok := oldValue.(map[string]any); ok {
+			return d.diffMaps(pointer, oldTypedValue, newTypedValue)
+		}
+
+	case []any:
+		if oldTypedValue, ok := oldValue.([]any); ok {
+			if key, ok := d.getMergeKey(pointer, oldTypedValue, newTypedValue); ok {
+				return d.diffKeyedLists(pointer, key, oldTypedValue, newTypedValue)
+			}
+
+			return d.diffLists(pointer, oldTypedValue, newTypedValue)
+		}
+	}
+
+	if reflect.DeepEqual(oldValue, newValue) {
+		return nil, nil
+	}
+
+	operation, err := newValueOperation("replace", pointer, newValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return []jsonPatchOperation{operation}, nil
+}
+
+func (d listAwareDiffer) diffMaps(pointer []string, oldMap, newMap map[string]any) ([]jsonPatchOperation, error) {
+	keys := make([]string, 0, len(oldMap)+len(newMap))
+	for key := range oldMap {
+		keys = append(keys, key)
+	}
+	for key := range newMap {
+		if _, ok := oldMap[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var result []jsonPatchOperation
+	for _, key := range keys {
+		childPointer := appendPointer(pointer, key)
+		oldChild, inOld := oldMap[key]
+		newChild, inNew := newMap[key]
+
+		switch {
+		case !inNew:
+			result = append(result, jsonPatchOperation{Op: "remove", Path: formatPointer(childPointer)})
+
+		case !inOld:
+			operation, err := newValueOperation("add", childPointer, newChild)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, operation)
+
+		default:
+			operations, err := d.diff(childPointer, oldChild, newChild)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, operations...)
+		}
+	}
+
+	return result, nil
+}
+
+// diffLists diffs two lists by the position of their elements.
+func (d listAwareDiffer) diffLists(pointer []string, oldList, newList []any) ([]jsonPatchOperation, error) {
+	var result []jsonPatchOperation
+	for i := 0; i < len(oldList) && i < len(newList); i++ {
+		operations, err := d.diff(appendPointer(pointer, strconv.Itoa(i)), oldList[i], newList[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, operations...)
+	}
+
+	for i := len(oldList); i < len(newList); i++ {
+		operation, err := newValueOperation("add", appendPointer(pointer, "-"), newList[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, operation)
+	}
+
+	for i := len(oldList) - 1; i >= len(newList); i-- {
+		result = append(result, jsonPatchOperation{
+			Op:   "remove",
+			Path: formatPointer(appendPointer(pointer, strconv.Itoa(i))),
+		})
+	}
+
+	return result, nil
+}
+
+// diffKeyedLists diffs two lists by the key of their elements. The
+// removed elements are removed first, starting from the last one,
+// then the remaining elements are compared with the new ones in
+// order, adding the new elements and moving the reordered ones.
+func (d listAwareDiffer) diffKeyedLists(
+	pointer []string,
+	key string,
+	oldList, newList []any,
+) ([]jsonPatchOperation, error) {
+	newKeys := make(map[string]struct{}, len(newList))
+	for _, element := range newList {
+		newKeys[getElementKey(element, key)] = struct{}{}
+	}
+
+	var result []jsonPatchOperation
+
+	current := slices.Clone(oldList)
+	for i := len(current) - 1; i >= 0; i-- {
+		elementKey := getElementKey(current[i], key)
+		if _, ok := newKeys[elementKey]; ok {
+			continue
+		}
+
+		elementPointer := appendPointer(pointer, strconv.Itoa(i))
+		operations, err := d.withTestOperation(elementPointer, key, elementKey, []jsonPatchOperation{
+			{Op: "remove", Path: formatPointer(elementPointer)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, operations...)
+		current = slices.Delete(current, i, i+1)
+	}
+
+	for j, newElement := range newList {
+		elementKey := getElementKey(newElement, key)
+		elementPointer := appendPointer(pointer, strconv.Itoa(j))
+
+		position := slices.IndexFunc(current, func(element any) bool {
+			return getElementKey(element, key) == elementKey
+		})
+
+		if position < 0 {
+			addPointer := elementPointer
+			if j == len(current) {
+				addPointer = appendPointer(pointer, "-")
+			}
+
+			operation, err := newValueOperation("add", addPointer, newElement)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, operation)
+			current = slices.Insert(current, j, newElement)
+			continue
+		}
+
+		if position != j {
+			fromPointer := appendPointer(pointer, strconv.Itoa(position))
+			operations, err := d.withTestOperation(fromPointer, key, elementKey, []jsonPatchOperation{
+				{Op: "move", From: formatPointer(fromPointer), Path: formatPointer(elementPointer)},
+			})
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, operations...)
+
+			element := current[position]
+			current = slices.Delete(current, position, position+1)
+			current = slices.Insert(current, j, element)
+		}
+
+		operations, err := d.diff(elementPointer, current[j], newElement)
+		if err != nil {
+			return nil, err
+		}
+		if len(operations) > 0 {
+			operations, err = d.withTestOperation(elementPointer, key, elementKey, operations)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, operations...)
+		}
+	}
+
+	return result, nil
+}
+
+// withTestOperation prepends to the operations changing a list element
+// a "test" operation checking the key of the element.
+func (d listAwareDiffer) withTestOperation(
+	elementPointer []string,
+	key, elementKey string,
+	operations []jsonPatchOperation,
+) ([]jsonPatchOperation, error) {
+	if !d.testOperations {
+		return operations, nil
+	}
+
+	testOperation, err := newValueOperation("test", appendPointer(elementPointer, key), elementKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]jsonPatchOperation{testOperation}, operations...), nil
+}
+
+// getMergeKey gets the merge key of the list at the passed pointer.
+// Lists whose elements are not objects having a unique
+// string key are diffed by position.
+func (d listAwareDiffer) getMergeKey(pointer []string, oldList, newList []any) (string, bool) {
+	for _, mergeKey := range d.mergeKeys {
+		if !matchPointer(pointer, mergeKey.Path) {
+			continue
+		}
+
+		if hasUniqueKeys(oldList, mergeKey.Key) && hasUniqueKeys(newList, mergeKey.Key) {
+			return mergeKey.Key, true
+		}
+
+		return "", false
+	}
+
+	return "", false
+}
+
+func hasUniqueKeys(list []any, key string) bool {
+	keys := make(map[string]struct{}, len(list))
+	for _, element := range list {
+		object, ok := element.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		elementKey, ok := object[key].(string)
+		if !ok {
+			return false
+		}
+
+		if _, found := keys[elementKey]; found {
+			return false
+		}
+		keys[elementKey] = struct{}{}
+	}
+
+	return true
+}
+
+func getElementKey(element any, key string) string {
+	return element.(map[string]any)[key].(string)
+}
+
+func newValueOperation(op string, pointer []string, value any) (jsonPatchOperation, error) {
+	rawValue, err := json.Marshal(value)
+	if err != nil {
+		return jsonPatchOperation{}, fmt.Errorf("while marshalling the value of %s: %w", formatPointer(pointer), err)
+	}
+
+	return jsonPatchOperation{Op: op, Path: formatPointer(pointer), Value: rawValue}, nil
+}
+
+func unmarshalContent(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var result any
+	if err := decoder.Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// matchPointer checks if a pointer matches a JSON pointer pattern,
+// where a "*" segment matches any single segment.
+func matchPointer(pointer []string, pattern string) bool {
+	patternSegments := strings.Split(pattern, "/")[1:]
+	if len(patternSegments) != len(pointer) {
+		return false
+	}
+
+	for i, patternSegment := range patternSegments {
+		if patternSegment != "*" && patternSegment != escapePointerSegment(pointer[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func appendPointer(pointer []string, segment string) []string {
+	return append(slices.Clip(pointer), segment)
+}
+
+func formatPointer(pointer []string) string {
+	var builder strings.Builder
+	for _, segment := range pointer {
+		builder.WriteString("/")
+		builder.WriteString(escapePointerSegment(segment))
+	}
+
+	return builder.String()
+}
+
+var pointerSegmentEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapePointerSegment(segment string) string {
+	return pointerSegmentEscaper.Replace(segment)
+}
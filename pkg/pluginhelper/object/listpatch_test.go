@@ -0,0 +1,188 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CreateListAwarePatch", func() {
+	var (
+		pod        *corev1.Pod
+		updatedPod *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		pod = newTestPod()
+		updatedPod = pod.DeepCopy()
+	})
+
+	It("should insert elements at a stable position", func() {
+		updatedPod.Spec.Containers = append(
+			[]corev1.Container{{Name: "init", Image: "init:1"}},
+			updatedPod.Spec.Containers...,
+		)
+
+		patch, err := CreateListAwarePatch(updatedPod, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(patch)).To(MatchJSON(
+			`[{"op":"add","path":"/spec/containers/0","value":{"name":"init","image":"init:1","resources":{}}}]`))
+	})
+
+	It("should append elements at the end of the list", func() {
+		updatedPod.Spec.Volumes = append(updatedPod.Spec.Volumes, corev1.Volume{Name: "scratch"})
+
+		patch, err := CreateListAwarePatch(updatedPod, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(patch)).To(MatchJSON(`[{"op":"add","path":"/spec/volumes","value":[{"name":"scratch"}]}]`))
+
+		pod = updatedPod.DeepCopy()
+		updatedPod.Spec.Volumes = append(updatedPod.Spec.Volumes, corev1.Volume{Name: "other"})
+
+		patch, err = CreateListAwarePatch(updatedPod, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(patch)).To(MatchJSON(`[{"op":"add","path":"/spec/volumes/-","value":{"name":"other"}}]`))
+	})
+
+	It("should guard the changes to existing elements with test operations", func() {
+		updatedPod.Spec.Containers[1].Env = []corev1.EnvVar{{Name: "B", Value: "2"}}
+
+		patch, err := CreateListAwarePatch(updatedPod, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(patch)).To(MatchJSON(`[
+			{"op":"test","path":"/spec/containers/1/name","value":"sidecar"},
+			{"op":"add","path":"/spec/containers/1/env","value":[{"name":"B","value":"2"}]}
+		]`))
+	})
+
+	It("should remove elements by key", func() {
+		updatedPod.Spec.Containers = updatedPod.Spec.Containers[1:]
+
+		patch, err := CreateListAwarePatch(updatedPod, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(patch)).To(MatchJSON(`[
+			{"op":"test","path":"/spec/containers/0/name","value":"postgres"},
+			{"op":"remove","path":"/spec/containers/0"}
+		]`))
+	})
+
+	It("should move reordered elements", func() {
+		updatedPod.Spec.Containers[0], updatedPod.Spec.Containers[1] =
+			updatedPod.Spec.Containers[1], updatedPod.Spec.Containers[0]
+
+		patch, err := CreateListAwarePatch(updatedPod, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(patch)).To(MatchJSON(`[
+			{"op":"test","path":"/spec/containers/1/name","value":"sidecar"},
+			{"op":"move","from":"/spec/containers/1","path":"/spec/containers/0"}
+		]`))
+	})
+
+	It("should diff the lists without a merge key by position", func() {
+		pod.Spec.Containers[0].Args = []string{"a", "b"}
+		updatedPod = pod.DeepCopy()
+		updatedPod.Spec.Containers[0].Args = []string{"a", "c", "d"}
+
+		patch, err := ListAwarePatcher{SkipTestOperations: true}.CreatePatch(updatedPod, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(patch)).To(MatchJSON(`[
+			{"op":"replace","path":"/spec/containers/0/args/1","value":"c"},
+			{"op":"add","path":"/spec/containers/0/args/-","value":"d"}
+		]`))
+	})
+
+	It("should use the passed merge keys", func() {
+		updatedPod.Spec.Containers = append(
+			[]corev1.Container{{Name: "init", Image: "init:1"}},
+			updatedPod.Spec.Containers...,
+		)
+
+		patch, err := ListAwarePatcher{MergeKeys: []ListMergeKey{}}.CreatePatch(updatedPod, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(patch)).To(ContainSubstring(`"op":"replace","path":"/spec/containers/0/name"`))
+	})
+
+	It("should make the patch fail when the list has been concurrently modified", func() {
+		updatedPod.Spec.Containers[1].Image = "sidecar:2"
+		patch, err := CreateListAwarePatch(updatedPod, pod)
+		Expect(err).ToNot(HaveOccurred())
+
+		concurrentlyModifiedPod := pod.DeepCopy()
+		concurrentlyModifiedPod.Spec.Containers = append(
+			[]corev1.Container{{Name: "other", Image: "other:1"}},
+			concurrentlyModifiedPod.Spec.Containers...,
+		)
+
+		_, err = ApplyPatch(concurrentlyModifiedPod, patch)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an empty patch for identical objects", func() {
+		patch, err := CreateListAwarePatch(updatedPod, pod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patch).To(BeEmpty())
+	})
+
+	It("should fail for nil objects", func() {
+		_, err := CreateListAwarePatch(nil, pod)
+		Expect(err).To(MatchError(ErrNilObject))
+	})
+
+	DescribeTable(
+		"applying the patch should yield the new object",
+		func(mutate func(pod *corev1.Pod)) {
+			mutate(updatedPod)
+
+			patch, err := CreateListAwarePatch(updatedPod, pod)
+			Expect(err).ToNot(HaveOccurred())
+
+			patchedPod, err := ApplyPatch(pod, patch)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(equality.Semantic.DeepEqual(patchedPod, updatedPod)).To(BeTrue())
+		},
+		Entry("inserting and removing containers", func(pod *corev1.Pod) {
+			pod.Spec.Containers = []corev1.Container{
+				{Name: "first", Image: "first:1"},
+				pod.Spec.Containers[1],
+				{Name: "last", Image: "last:1"},
+			}
+		}),
+		Entry("reordering and changing containers", func(pod *corev1.Pod) {
+			pod.Spec.Containers = []corev1.Container{
+				{Name: "new", Image: "new:1"},
+				pod.Spec.Containers[1],
+				pod.Spec.Containers[0],
+			}
+			pod.Spec.Containers[2].Image = "postgres:17"
+			pod.Spec.Containers[2].Env = append(pod.Spec.Containers[2].Env, corev1.EnvVar{Name: "0", Value: "0"})
+		}),
+		Entry("removing every container", func(pod *corev1.Pod) {
+			pod.Spec.Containers = nil
+		}),
+		Entry("changing the metadata", func(pod *corev1.Pod) {
+			pod.Labels["cnpg.io/role"] = "primary"
+			delete(pod.Annotations, "note")
+		}),
+	)
+})
@@ -20,10 +20,26 @@ SPDX-License-Identifier: Apache-2.0
 package object
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 
+	jsonpatchv5 "github.com/evanphx/json-patch/v5"
 	"github.com/snorwin/jsonpatch"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+var (
+	// ErrNilObject is raised when a nil object is passed
+	// to the patch functions.
+	ErrNilObject = errors.New("cannot create a patch for a nil object")
+
+	// ErrUnsupportedPatchType is raised when the requested
+	// patch type is not supported.
+	ErrUnsupportedPatchType = errors.New("unsupported patch type")
 )
 
 // CreatePatch creates a JSON patch from the diff between the old and new object.
@@ -35,3 +51,88 @@ func CreatePatch(newObject, oldObject runtime.Object) ([]byte, error) {
 
 	return []byte(ptc.String()), nil
 }
+
+// CreatePatchOfType creates a patch of the requested type from the diff
+// between the old and new object. The supported patch types are:
+//
+//   - types.JSONPatchType: an RFC 6902 JSON patch, like CreatePatch
+//   - types.MergePatchType: an RFC 7386 JSON merge patch
+//   - types.StrategicMergePatchType: a strategic merge patch, merging
+//     lists by the keys declared in the Go type of the objects, i.e.
+//     the containers of a Pod by their name. Lists of types not
+//     declaring a merge key, such as the CloudNativePG ones,
+//     are replaced
+//
+// An empty patch is returned when the objects are equal.
+func CreatePatchOfType(patchType types.PatchType, newObject, oldObject runtime.Object) ([]byte, error) {
+	switch patchType {
+	case types.JSONPatchType:
+		return CreatePatch(newObject, oldObject)
+	case types.MergePatchType:
+		return CreateMergePatch(newObject, oldObject)
+	case types.StrategicMergePatchType:
+		return CreateStrategicMergePatch(newObject, oldObject)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPatchType, patchType)
+	}
+}
+
+// CreateMergePatch creates an RFC 7386 JSON merge patch from the diff
+// between the old and new object.
+func CreateMergePatch(newObject, oldObject runtime.Object) ([]byte, error) {
+	newJSON, oldJSON, err := marshalObjects(newObject, oldObject)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := jsonpatchv5.CreateMergePatch(oldJSON, newJSON)
+	if err != nil {
+		return nil, fmt.Errorf("while creating JSON merge patch: %w", err)
+	}
+
+	return normalizeMergePatch(patch), nil
+}
+
+// CreateStrategicMergePatch creates a strategic merge patch from the diff
+// between the old and new object, which must have the same type.
+func CreateStrategicMergePatch(newObject, oldObject runtime.Object) ([]byte, error) {
+	newJSON, oldJSON, err := marshalObjects(newObject, oldObject)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := strategicpatch.CreateTwoWayMergePatch(oldJSON, newJSON, newObject)
+	if err != nil {
+		return nil, fmt.Errorf("while creating strategic merge patch: %w", err)
+	}
+
+	return normalizeMergePatch(patch), nil
+}
+
+func marshalObjects(newObject, oldObject runtime.Object) ([]byte, []byte, error) {
+	if newObject == nil || oldObject == nil {
+		return nil, nil, ErrNilObject
+	}
+
+	newJSON, err := json.Marshal(newObject)
+	if err != nil {
+		return nil, nil, fmt.Errorf("while marshalling the new object: %w", err)
+	}
+
+	oldJSON, err := json.Marshal(oldObject)
+	if err != nil {
+		return nil, nil, fmt.Errorf("while marshalling the old object: %w", err)
+	}
+
+	return newJSON, oldJSON, nil
+}
+
+// normalizeMergePatch returns an empty patch when the merge patch
+// contains no change, consistently with CreatePatch.
+func normalizeMergePatch(patch []byte) []byte {
+	if bytes.Equal(patch, []byte("{}")) {
+		return []byte{}
+	}
+
+	return patch
+}
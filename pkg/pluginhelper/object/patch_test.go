@@ -20,8 +20,14 @@ SPDX-License-Identifier: Apache-2.0
 package object
 
 import (
+	"encoding/json"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -104,3 +110,123 @@ var _ = Describe("CreatePatch", func() {
 		),
 	)
 })
+
+func newTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pod",
+			Labels:      map[string]string{"app": "test"},
+			Annotations: map[string]string{"note": "value"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "postgres",
+					Image: "postgres:16",
+					Env:   []corev1.EnvVar{{Name: "A", Value: "1"}},
+				},
+				{Name: "sidecar", Image: "sidecar:1"},
+			},
+		},
+	}
+}
+
+var _ = Describe("CreatePatchOfType", func() {
+	patchTypes := []types.PatchType{
+		types.JSONPatchType,
+		types.MergePatchType,
+		types.StrategicMergePatchType,
+	}
+
+	DescribeTable(
+		"applying every patch type should yield the same Pod",
+		func(mutate func(pod *corev1.Pod)) {
+			oldPod := newTestPod()
+			newPod := oldPod.DeepCopy()
+			mutate(newPod)
+
+			for _, patchType := range patchTypes {
+				patch, err := CreatePatchOfType(patchType, newPod, oldPod)
+				Expect(err).ToNot(HaveOccurred())
+
+				patchedPod, err := ApplyPatchOfType(patchType, oldPod, patch)
+				Expect(err).ToNot(HaveOccurred())
+				// empty and nil lists are considered equal, as they are
+				// encoded in the same way
+				Expect(equality.Semantic.DeepEqual(patchedPod, newPod)).To(BeTrue(), "patch type %s", patchType)
+			}
+		},
+		Entry("changing an image", func(pod *corev1.Pod) {
+			pod.Spec.Containers[0].Image = "postgres:17"
+		}),
+		Entry("adding a container", func(pod *corev1.Pod) {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "new", Image: "new:1"})
+		}),
+		Entry("removing a container", func(pod *corev1.Pod) {
+			pod.Spec.Containers = pod.Spec.Containers[1:]
+		}),
+		Entry("adding an environment variable", func(pod *corev1.Pod) {
+			pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{Name: "B", Value: "2"})
+		}),
+		Entry("changing the metadata", func(pod *corev1.Pod) {
+			pod.Labels["role"] = "primary"
+			delete(pod.Annotations, "note")
+		}),
+		Entry("not changing anything", func(*corev1.Pod) {}),
+	)
+
+	It("should merge the containers by name with strategic merge patches", func() {
+		oldPod := newTestPod()
+		newPod := oldPod.DeepCopy()
+		newPod.Spec.Containers[1].Image = "sidecar:2"
+
+		patch, err := CreatePatchOfType(types.StrategicMergePatchType, newPod, oldPod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(patch)).To(MatchJSON(
+			`{"spec":{"$setElementOrder/containers":[{"name":"postgres"},{"name":"sidecar"}],` +
+				`"containers":[{"image":"sidecar:2","name":"sidecar"}]}}`))
+	})
+
+	It("should replace the lists with merge patches", func() {
+		oldPod := newTestPod()
+		newPod := oldPod.DeepCopy()
+		newPod.Spec.Containers[1].Image = "sidecar:2"
+
+		patch, err := CreatePatchOfType(types.MergePatchType, newPod, oldPod)
+		Expect(err).ToNot(HaveOccurred())
+
+		var content map[string]map[string][]any
+		Expect(json.Unmarshal(patch, &content)).To(Succeed())
+		Expect(content["spec"]["containers"]).To(HaveLen(2))
+	})
+
+	It("should return an empty patch for identical objects", func() {
+		for _, patchType := range patchTypes {
+			patch, err := CreatePatchOfType(patchType, newTestPod(), newTestPod())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(patch).To(BeEmpty())
+		}
+	})
+
+	It("should support CloudNativePG objects", func() {
+		oldCluster := &apiv1.Cluster{Spec: apiv1.ClusterSpec{Instances: 1}}
+		newCluster := &apiv1.Cluster{Spec: apiv1.ClusterSpec{Instances: 3}}
+
+		patch, err := CreatePatchOfType(types.StrategicMergePatchType, newCluster, oldCluster)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(patch)).To(MatchJSON(`{"spec":{"instances":3}}`))
+	})
+
+	It("should fail for nil objects", func() {
+		_, err := CreatePatchOfType(types.MergePatchType, nil, newTestPod())
+		Expect(err).To(MatchError(ErrNilObject))
+
+		_, err = CreatePatchOfType(types.StrategicMergePatchType, newTestPod(), nil)
+		Expect(err).To(MatchError(ErrNilObject))
+	})
+
+	It("should fail for unsupported patch types", func() {
+		_, err := CreatePatchOfType(types.ApplyYAMLPatchType, newTestPod(), newTestPod())
+		Expect(err).To(MatchError(ErrUnsupportedPatchType))
+	})
+})
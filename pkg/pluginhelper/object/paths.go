@@ -0,0 +1,137 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	jsonpatchv5 "github.com/evanphx/json-patch/v5"
+)
+
+// DisallowedPatchPathsError is raised when a JSON patch touches
+// paths outside the allowed ones.
+type DisallowedPatchPathsError struct {
+	// Paths are the JSON pointers of the disallowed paths
+	Paths []string
+}
+
+// Error implements the error interface.
+func (e *DisallowedPatchPathsError) Error() string {
+	return fmt.Sprintf("patch touches disallowed paths: %s", strings.Join(e.Paths, ", "))
+}
+
+// CheckPatchPaths checks that every operation of an RFC 6902 JSON patch,
+// such as the ones created by CreatePatch, only touches the allowed
+// paths or their children. Allowed paths are JSON pointers, where a
+// "*" segment matches any single segment, i.e.
+//
+//	err := object.CheckPatchPaths(patch, "/spec/containers/*/env", "/metadata/labels")
+//
+// A DisallowedPatchPathsError is returned when a path is not allowed.
+func CheckPatchPaths(patch []byte, allowedPaths ...string) error {
+	_, _, disallowedPaths, err := splitPatchOperations(patch, allowedPaths)
+	if err != nil {
+		return err
+	}
+
+	if len(disallowedPaths) > 0 {
+		return &DisallowedPatchPathsError{Paths: disallowedPaths}
+	}
+
+	return nil
+}
+
+// splitPatchOperations decodes an RFC 6902 JSON patch, returning the
+// operations only touching the allowed paths, the other operations and
+// the disallowed paths they touch.
+func splitPatchOperations(
+	patch []byte,
+	allowedPaths []string,
+) (jsonpatchv5.Patch, jsonpatchv5.Patch, []string, error) {
+	if len(patch) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	decodedPatch, err := jsonpatchv5.DecodePatch(patch)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("while decoding JSON patch: %w", err)
+	}
+
+	allowedOperations := make(jsonpatchv5.Patch, 0, len(decodedPatch))
+	var disallowedOperations jsonpatchv5.Patch
+	var disallowedPaths []string
+	for _, operation := range decodedPatch {
+		path, err := operation.Path()
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("while reading JSON patch operation path: %w", err)
+		}
+		paths := []string{path}
+
+		if from, err := operation.From(); err == nil {
+			paths = append(paths, from)
+		}
+
+		allowed := true
+		for _, path := range paths {
+			if isPathAllowed(path, allowedPaths) {
+				continue
+			}
+
+			allowed = false
+			if !slices.Contains(disallowedPaths, path) {
+				disallowedPaths = append(disallowedPaths, path)
+			}
+		}
+
+		if allowed {
+			allowedOperations = append(allowedOperations, operation)
+		} else {
+			disallowedOperations = append(disallowedOperations, operation)
+		}
+	}
+
+	return allowedOperations, disallowedOperations, disallowedPaths, nil
+}
+
+func isPathAllowed(path string, allowedPaths []string) bool {
+	segments := strings.Split(path, "/")
+	for _, allowedPath := range allowedPaths {
+		allowedSegments := strings.Split(allowedPath, "/")
+		if len(allowedSegments) > len(segments) {
+			continue
+		}
+
+		matches := true
+		for i, allowedSegment := range allowedSegments {
+			if allowedSegment != "*" && allowedSegment != segments[i] {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
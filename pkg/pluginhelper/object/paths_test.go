@@ -0,0 +1,55 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CheckPatchPaths", func() {
+	const patch = `[
+		{"op":"replace","path":"/spec/containers/0/image","value":"postgres:17"},
+		{"op":"add","path":"/metadata/labels/role","value":"primary"},
+		{"op":"move","from":"/metadata/annotations/a","path":"/metadata/labels/a"}
+	]`
+
+	It("should accept patches touching only the allowed paths", func() {
+		Expect(CheckPatchPaths([]byte(patch), "/spec/containers", "/metadata")).To(Succeed())
+		Expect(CheckPatchPaths([]byte(patch), "/spec/containers/*/image", "/metadata")).To(Succeed())
+		Expect(CheckPatchPaths(nil)).To(Succeed())
+	})
+
+	It("should report the disallowed paths", func() {
+		err := CheckPatchPaths([]byte(patch), "/spec/containers/*/env", "/metadata/labels")
+		Expect(err).To(MatchError(&DisallowedPatchPathsError{
+			Paths: []string{"/spec/containers/0/image", "/metadata/annotations/a"},
+		}))
+	})
+
+	It("should match whole segments only", func() {
+		err := CheckPatchPaths([]byte(`[{"op":"remove","path":"/spec/containersExtra"}]`), "/spec/containers")
+		Expect(err).To(MatchError(&DisallowedPatchPathsError{Paths: []string{"/spec/containersExtra"}}))
+	})
+
+	It("should fail for invalid patches", func() {
+		Expect(CheckPatchPaths([]byte(`{}`), "/spec")).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,166 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	// ErrInvalidSidecarPlacement is raised when the placement of a
+	// sidecar is not correctly defined.
+	ErrInvalidSidecarPlacement = errors.New("invalid sidecar placement")
+
+	// ErrSidecarReferenceNotFound is raised when the init container
+	// the sidecar is placed relative to is not in the Pod.
+	ErrSidecarReferenceNotFound = errors.New("sidecar placement reference not found")
+
+	// ErrInconsistentSidecarOrder is raised when an existing sidecar,
+	// which is not reconciled, is not placed as requested.
+	ErrInconsistentSidecarOrder = errors.New("existing sidecar not placed as requested")
+)
+
+// SidecarPlacement is where a native sidecar is placed among the init
+// containers. As native sidecars are started in order, this controls
+// which containers are running when the sidecar starts.
+type SidecarPlacement int
+
+const (
+	// PlaceLast appends the sidecar to the init containers. An existing
+	// sidecar is not moved.
+	PlaceLast SidecarPlacement = iota
+
+	// PlaceFirst places the sidecar before every other init container.
+	PlaceFirst
+
+	// PlaceBefore places the sidecar before the init container
+	// named in the placement reference.
+	PlaceBefore
+
+	// PlaceAfter places the sidecar after the init container
+	// named in the placement reference.
+	PlaceAfter
+)
+
+// String implements the fmt.Stringer interface.
+func (p SidecarPlacement) String() string {
+	switch p {
+	case PlaceLast:
+		return "last"
+	case PlaceFirst:
+		return "first"
+	case PlaceBefore:
+		return "before"
+	case PlaceAfter:
+		return "after"
+	default:
+		return fmt.Sprintf("SidecarPlacement(%d)", int(p))
+	}
+}
+
+// validatePlacement checks that the placement options of a sidecar are
+// consistent with each other and with the passed init containers.
+func (o SidecarOptions) validatePlacement(sidecarName string, initContainers []corev1.Container) error {
+	switch o.Placement {
+	case PlaceLast, PlaceFirst:
+		if o.PlacementReference != "" {
+			return fmt.Errorf("%w: no reference is allowed when placing the sidecar %s",
+				ErrInvalidSidecarPlacement, o.Placement)
+		}
+
+		return nil
+
+	case PlaceBefore, PlaceAfter:
+		if o.PlacementReference == "" {
+			return fmt.Errorf("%w: a reference is required when placing the sidecar %s",
+				ErrInvalidSidecarPlacement, o.Placement)
+		}
+
+		if o.PlacementReference == sidecarName {
+			return fmt.Errorf("%w: the sidecar cannot be placed relative to itself", ErrInvalidSidecarPlacement)
+		}
+
+		if findContainerIndex(initContainers, o.PlacementReference) < 0 {
+			return fmt.Errorf("%w: %s", ErrSidecarReferenceNotFound, o.PlacementReference)
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidSidecarPlacement, o.Placement)
+	}
+}
+
+// isPlacedAt checks whether a sidecar at the passed index of the
+// containers satisfies the placement.
+func (o SidecarOptions) isPlacedAt(containers []corev1.Container, idx int) bool {
+	switch o.Placement {
+	case PlaceFirst:
+		return idx == 0
+	case PlaceBefore:
+		return idx < findContainerIndex(containers, o.PlacementReference)
+	case PlaceAfter:
+		return idx > findContainerIndex(containers, o.PlacementReference)
+	default:
+		return true
+	}
+}
+
+// insertionIndex is the index where the sidecar is to be inserted
+// into containers not including it.
+func (o SidecarOptions) insertionIndex(containers []corev1.Container) int {
+	switch o.Placement {
+	case PlaceFirst:
+		return 0
+	case PlaceBefore:
+		return findContainerIndex(containers, o.PlacementReference)
+	case PlaceAfter:
+		return findContainerIndex(containers, o.PlacementReference) + 1
+	default:
+		return len(containers)
+	}
+}
+
+// placeSidecar inserts the sidecar in the containers, or moves the
+// existing one at the passed index when it is not correctly placed.
+func (o SidecarOptions) placeSidecar(
+	containers []corev1.Container,
+	sidecar corev1.Container,
+	idx int,
+) []corev1.Container {
+	if idx >= 0 {
+		if o.isPlacedAt(containers, idx) {
+			return containers
+		}
+		containers = slices.Delete(containers, idx, idx+1)
+	}
+
+	return slices.Insert(containers, o.insertionIndex(containers), sidecar)
+}
+
+func findContainerIndex(containers []corev1.Container, name string) int {
+	return slices.IndexFunc(containers, func(container corev1.Container) bool {
+		return container.Name == name
+	})
+}
@@ -21,8 +21,12 @@ package object
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/utils/ptr"
 )
 
@@ -33,6 +37,10 @@ const (
 	postgresContainerName = "postgres"
 )
 
+// PostgresContainerName is the name of the PostgreSQL container
+// in the instance Pods.
+const PostgresContainerName = postgresContainerName
+
 var (
 	// ErrNoPostgresContainerFound is raised when there's no PostgreSQL container
 	// in the passed instance Pod.
@@ -40,8 +48,47 @@ var (
 
 	// ErrNilPodPassed is raised when a nil Pod is passed to a function requiring it.
 	ErrNilPodPassed = errors.New("nil pod passed")
+
+	// ErrPluginVolumeConflict is raised when the plugin volume already
+	// exists with a different medium or size limit than the requested ones.
+	ErrPluginVolumeConflict = errors.New("plugin volume already exists with a different configuration")
 )
 
+// PluginVolumeOptions configures the plugin volume injected into
+// a CNPG Pod. The zero value of each field selects the default,
+// which is what the operator expects.
+type PluginVolumeOptions struct {
+	// Name is the name of the volume. Defaults to "plugins"
+	Name string
+
+	// MountPath is where the volume is mounted. Defaults to "/plugins"
+	MountPath string
+
+	// Medium is the storage medium of the emptyDir volume, i.e.
+	// corev1.StorageMediumMemory. Defaults to the node disk
+	Medium corev1.StorageMedium
+
+	// SizeLimit is the size limit of the emptyDir volume.
+	// Defaults to no limit
+	SizeLimit *resource.Quantity
+
+	// ExtraContainers are the names of the containers, or init
+	// containers, where the volume is mounted besides the
+	// PostgreSQL one. Missing containers are ignored
+	ExtraContainers []string
+}
+
+func (o PluginVolumeOptions) withDefaults() PluginVolumeOptions {
+	if o.Name == "" {
+		o.Name = pluginVolumeName
+	}
+	if o.MountPath == "" {
+		o.MountPath = pluginMountPath
+	}
+
+	return o
+}
+
 // InjectPluginVolume injects the plugin volume into a CNPG Pod.
 func InjectPluginVolume(pod *corev1.Pod) {
 	InjectPluginVolumeSpec(&pod.Spec)
@@ -49,35 +96,129 @@ func InjectPluginVolume(pod *corev1.Pod) {
 
 // InjectPluginVolumeSpec injects the plugin volume into a CNPG Pod spec.
 func InjectPluginVolumeSpec(spec *corev1.PodSpec) {
+	// The default options never conflict with an existing volume
+	_ = InjectPluginVolumeSpecWithOptions(spec, PluginVolumeOptions{})
+}
+
+// InjectPluginVolumeWithOptions refer to InjectPluginVolumeSpecWithOptions.
+func InjectPluginVolumeWithOptions(pod *corev1.Pod, options PluginVolumeOptions) error {
+	return InjectPluginVolumeSpecWithOptions(&pod.Spec, options)
+}
+
+// InjectPluginVolumeSpecWithOptions injects the plugin volume configured
+// by the passed options into a CNPG Pod spec, mounting it into the
+// PostgreSQL container and the requested extra containers.
+//
+// The volume is not changed if the Pod spec already has it, and it is
+// only mounted into the containers not already mounting it. When the
+// options request a medium or a size limit the existing volume
+// doesn't have, ErrPluginVolumeConflict is returned and the Pod spec
+// is left unchanged. Containers that don't exist yet are not mounted:
+// use SidecarOptions.PluginVolume to mount the volume into a sidecar.
+func InjectPluginVolumeSpecWithOptions(spec *corev1.PodSpec, options PluginVolumeOptions) error {
+	options = options.withDefaults()
+
 	foundPluginVolume := false
 	for i := range spec.Volumes {
-		if spec.Volumes[i].Name == pluginVolumeName {
-			foundPluginVolume = true
+		if spec.Volumes[i].Name != options.Name {
+			continue
 		}
-	}
 
-	if foundPluginVolume {
-		return
+		foundPluginVolume = true
+		if !options.isSatisfiedBy(&spec.Volumes[i]) {
+			return fmt.Errorf("%w: %s", ErrPluginVolumeConflict, options.Name)
+		}
 	}
 
-	spec.Volumes = append(spec.Volumes, corev1.Volume{
-		Name: pluginVolumeName,
-		VolumeSource: corev1.VolumeSource{
-			EmptyDir: &corev1.EmptyDirVolumeSource{},
-		},
-	})
-
-	for i := range spec.Containers {
-		if spec.Containers[i].Name == postgresContainerName {
-			spec.Containers[i].VolumeMounts = append(
-				spec.Containers[i].VolumeMounts,
-				corev1.VolumeMount{
-					Name:      pluginVolumeName,
-					MountPath: pluginMountPath,
+	if !foundPluginVolume {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: options.Name,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium:    options.Medium,
+					SizeLimit: options.SizeLimit,
 				},
-			)
+			},
+		})
+	}
+
+	targetContainers := append([]string{postgresContainerName}, options.ExtraContainers...)
+	for _, containers := range [][]corev1.Container{spec.Containers, spec.InitContainers} {
+		for i := range containers {
+			if slices.Contains(targetContainers, containers[i].Name) {
+				injectVolumeMount(&containers[i], options.Name, options.MountPath)
+			}
 		}
 	}
+
+	return nil
+}
+
+// isSatisfiedBy checks if an existing volume has the medium
+// and the size limit requested by the options.
+func (o PluginVolumeOptions) isSatisfiedBy(volume *corev1.Volume) bool {
+	if o.Medium == "" && o.SizeLimit == nil {
+		return true
+	}
+
+	emptyDir := volume.EmptyDir
+	if emptyDir == nil || emptyDir.Medium != o.Medium {
+		return false
+	}
+
+	if o.SizeLimit == nil {
+		return true
+	}
+
+	return emptyDir.SizeLimit != nil && emptyDir.SizeLimit.Cmp(*o.SizeLimit) == 0
+}
+
+func injectVolumeMount(container *corev1.Container, volumeName, mountPath string) {
+	for i := range container.VolumeMounts {
+		if container.VolumeMounts[i].Name == volumeName {
+			return
+		}
+	}
+
+	container.VolumeMounts = append(
+		container.VolumeMounts,
+		corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: mountPath,
+		},
+	)
+}
+
+// SidecarOptions configures how a plugin sidecar is injected.
+type SidecarOptions struct {
+	// PostgresVolumeMounts selects the volume mounts of the PostgreSQL
+	// container propagated to the sidecar. When nil, no volume
+	// mount is propagated
+	PostgresVolumeMounts PostgresVolumeMountSelector
+
+	// Placement is where the sidecar is placed among the init
+	// containers. Defaults to PlaceLast
+	Placement SidecarPlacement
+
+	// PlacementReference is the name of the init container the
+	// sidecar is placed relative to when using PlaceBefore or
+	// PlaceAfter
+	PlacementReference string
+
+	// PluginVolume configures the plugin volume injected together
+	// with the sidecar. Extra containers naming the sidecar are
+	// mounted after the sidecar is injected
+	PluginVolume PluginVolumeOptions
+}
+
+// newSidecarOptions creates the options equivalent to the
+// injectPostgresVolumeMounts flag.
+func newSidecarOptions(injectPostgresVolumeMounts bool) SidecarOptions {
+	if injectPostgresVolumeMounts {
+		return SidecarOptions{PostgresVolumeMounts: AllPostgresVolumeMounts}
+	}
+
+	return SidecarOptions{}
 }
 
 // InjectPluginSidecar refer to InjectPluginSidecarSpec.
@@ -104,6 +245,20 @@ func InjectPluginSidecarInitContainer(pod *corev1.Pod,
 	return InjectPluginInitContainerSidecarSpec(&pod.Spec, sidecar, injectPostgresVolumeMounts)
 }
 
+// InjectPluginSidecarInitContainerWithOptions refer to
+// InjectPluginInitContainerSidecarSpecWithOptions.
+func InjectPluginSidecarInitContainerWithOptions(
+	pod *corev1.Pod,
+	sidecar *corev1.Container,
+	options SidecarOptions,
+) error {
+	if pod == nil {
+		return ErrNilPodPassed
+	}
+
+	return InjectPluginInitContainerSidecarSpecWithOptions(&pod.Spec, sidecar, options)
+}
+
 // InjectPluginSidecarSpec injects a plugin sidecar into a CNPG Pod spec.
 //
 // If the "injectPostgresVolumeMount" flag is true, this will append all the volume
@@ -120,7 +275,7 @@ func InjectPluginSidecarSpec(spec *corev1.PodSpec, sidecar *corev1.Container, in
 		return &spec.Containers
 	}
 
-	return injectSidecar(spec, sidecar, injectPostgresVolumeMounts, fetcher)
+	return injectSidecar(spec, sidecar, newSidecarOptions(injectPostgresVolumeMounts), false, fetcher)
 }
 
 // InjectPluginInitContainerSidecarSpec injects a plugin sidecar into a CNPG Pod spec as
@@ -133,27 +288,83 @@ func InjectPluginSidecarSpec(spec *corev1.PodSpec, sidecar *corev1.Container, in
 //
 // Besides the value of "injectPostgresVolumeMount", the plugin volume
 // will always be injected in the PostgreSQL container.
+//
+// Use InjectPluginInitContainerSidecarSpecWithOptions to propagate
+// only some of the volume mounts.
 func InjectPluginInitContainerSidecarSpec(
 	spec *corev1.PodSpec, sidecar *corev1.Container, injectPostgresVolumeMounts bool,
 ) error {
-	fetcher := func(spec *corev1.PodSpec, sidecar *corev1.Container) *[]corev1.Container {
-		// ensure the sidecar has the correct restartPolicy
-		sidecar.RestartPolicy = ptr.To(corev1.ContainerRestartPolicyAlways)
+	return InjectPluginInitContainerSidecarSpecWithOptions(spec, sidecar, newSidecarOptions(injectPostgresVolumeMounts))
+}
 
-		if spec.InitContainers == nil {
-			spec.InitContainers = []corev1.Container{}
-		}
+// InjectPluginInitContainerSidecarSpecWithOptions injects a plugin sidecar
+// into a CNPG Pod spec as an InitContainer, like
+// InjectPluginInitContainerSidecarSpec, propagating the volume mounts
+// of the PostgreSQL container selected by the passed options.
+//
+// This allows granting the sidecar only the access it needs, i.e.
+// mounting the PGDATA volume read-only.
+func InjectPluginInitContainerSidecarSpecWithOptions(
+	spec *corev1.PodSpec, sidecar *corev1.Container, options SidecarOptions,
+) error {
+	return injectSidecar(spec, sidecar, options, false, initContainersFetcher)
+}
 
-		return &spec.InitContainers
+// ReconcilePluginSidecarInitContainer refer to
+// ReconcilePluginInitContainerSidecarSpec.
+func ReconcilePluginSidecarInitContainer(
+	pod *corev1.Pod,
+	sidecar *corev1.Container,
+	options SidecarOptions,
+) (bool, error) {
+	if pod == nil {
+		return false, ErrNilPodPassed
+	}
+
+	return ReconcilePluginInitContainerSidecarSpec(&pod.Spec, sidecar, options)
+}
+
+// ReconcilePluginInitContainerSidecarSpec injects a plugin sidecar into a
+// CNPG Pod spec as an InitContainer, like
+// InjectPluginInitContainerSidecarSpecWithOptions. When the Pod spec
+// already has a container with the same name, its image, command,
+// arguments, environment, resources and volume mounts are updated in
+// place, so that an upgraded plugin can replace its old sidecar.
+//
+// The returned flag reports whether the Pod spec has been changed, and
+// is meant to be used in the Pod patch lifecycle hook to avoid
+// creating empty patches.
+func ReconcilePluginInitContainerSidecarSpec(
+	spec *corev1.PodSpec, sidecar *corev1.Container, options SidecarOptions,
+) (bool, error) {
+	if spec == nil || sidecar == nil {
+		return false, nil
+	}
+
+	originalSpec := spec.DeepCopy()
+	if err := injectSidecar(spec, sidecar, options, true, initContainersFetcher); err != nil {
+		return false, err
 	}
 
-	return injectSidecar(spec, sidecar, injectPostgresVolumeMounts, fetcher)
+	return !equality.Semantic.DeepEqual(originalSpec, spec), nil
+}
+
+func initContainersFetcher(spec *corev1.PodSpec, sidecar *corev1.Container) *[]corev1.Container {
+	// ensure the sidecar has the correct restartPolicy
+	sidecar.RestartPolicy = ptr.To(corev1.ContainerRestartPolicyAlways)
+
+	if spec.InitContainers == nil {
+		spec.InitContainers = []corev1.Container{}
+	}
+
+	return &spec.InitContainers
 }
 
 func injectSidecar(
 	spec *corev1.PodSpec,
 	sidecar *corev1.Container,
-	injectPostgresVolumeMounts bool,
+	options SidecarOptions,
+	reconcile bool,
 	containerFetcher func(spec *corev1.PodSpec, sidecar *corev1.Container) *[]corev1.Container,
 ) error {
 	if spec == nil || sidecar == nil {
@@ -161,7 +372,15 @@ func injectSidecar(
 	}
 
 	modifiedSidecar := sidecar.DeepCopy()
-	InjectPluginVolumeSpec(spec)
+	targetContainers := containerFetcher(spec, modifiedSidecar)
+
+	if err := options.validatePlacement(modifiedSidecar.Name, *targetContainers); err != nil {
+		return err
+	}
+
+	if err := InjectPluginVolumeSpecWithOptions(spec, options.PluginVolume); err != nil {
+		return err
+	}
 
 	// Find PostgreSQL container and its volume mounts
 	volumeMounts, err := getPostgresVolumeMounts(spec)
@@ -169,21 +388,55 @@ func injectSidecar(
 		return err
 	}
 
-	targetContainers := containerFetcher(spec, modifiedSidecar)
+	for _, volumeMount := range options.PostgresVolumeMounts.Select(volumeMounts) {
+		if !hasMountPath(modifiedSidecar, volumeMount.MountPath) {
+			modifiedSidecar.VolumeMounts = append(modifiedSidecar.VolumeMounts, volumeMount)
+		}
+	}
+
+	idx := findContainerIndex(*targetContainers, modifiedSidecar.Name)
+	if idx >= 0 {
+		if !reconcile {
+			if !options.isPlacedAt(*targetContainers, idx) {
+				return fmt.Errorf("%w: %s", ErrInconsistentSidecarOrder, modifiedSidecar.Name)
+			}
 
-	for _, container := range *targetContainers {
-		if container.Name == modifiedSidecar.Name {
 			return nil
 		}
-	}
 
-	if injectPostgresVolumeMounts {
-		modifiedSidecar.VolumeMounts = append(modifiedSidecar.VolumeMounts, volumeMounts...)
+		updateSidecar(&(*targetContainers)[idx], modifiedSidecar)
+		modifiedSidecar = &(*targetContainers)[idx]
 	}
 
-	*targetContainers = append(*targetContainers, *modifiedSidecar)
+	*targetContainers = options.placeSidecar(*targetContainers, *modifiedSidecar, idx)
 
-	return nil
+	// Mount the plugin volume into the extra containers, which
+	// may include the sidecar that has just been injected
+	return InjectPluginVolumeSpecWithOptions(spec, options.PluginVolume)
+}
+
+// updateSidecar updates the fields of an existing sidecar that
+// are set by the plugin.
+func updateSidecar(existing, desired *corev1.Container) {
+	existing.Image = desired.Image
+	existing.ImagePullPolicy = desired.ImagePullPolicy
+	existing.Command = desired.Command
+	existing.Args = desired.Args
+	existing.Env = desired.Env
+	existing.EnvFrom = desired.EnvFrom
+	existing.Resources = desired.Resources
+	existing.VolumeMounts = desired.VolumeMounts
+	existing.RestartPolicy = desired.RestartPolicy
+}
+
+func hasMountPath(container *corev1.Container, mountPath string) bool {
+	for i := range container.VolumeMounts {
+		if container.VolumeMounts[i].MountPath == mountPath {
+			return true
+		}
+	}
+
+	return false
 }
 
 func getPostgresVolumeMounts(spec *corev1.PodSpec) ([]corev1.VolumeMount, error) {
@@ -21,6 +21,8 @@ package object
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -100,6 +102,81 @@ var _ = Describe("InjectPluginVolume", func() {
 	})
 })
 
+var _ = Describe("InjectPluginVolumeWithOptions", func() {
+	var pod *corev1.Pod
+
+	BeforeEach(func() {
+		pod = &corev1.Pod{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "bootstrap"}},
+				Containers:     []corev1.Container{{Name: "postgres"}, {Name: "sidecar"}},
+			},
+		}
+	})
+
+	It("should use the defaults expected by the operator", func() {
+		Expect(InjectPluginVolumeWithOptions(pod, PluginVolumeOptions{})).To(Succeed())
+		Expect(pod.Spec.Volumes).To(Equal([]corev1.Volume{
+			{
+				Name:         "plugins",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			},
+		}))
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(Equal([]corev1.VolumeMount{
+			{Name: "plugins", MountPath: "/plugins"},
+		}))
+	})
+
+	It("should inject the configured volume into the requested containers", func() {
+		sizeLimit := resource.MustParse("64Mi")
+		options := PluginVolumeOptions{
+			Name:            "scratch",
+			MountPath:       "/scratch",
+			Medium:          corev1.StorageMediumMemory,
+			SizeLimit:       &sizeLimit,
+			ExtraContainers: []string{"sidecar", "bootstrap", "missing"},
+		}
+
+		for range 2 {
+			Expect(InjectPluginVolumeWithOptions(pod, options)).To(Succeed())
+		}
+
+		Expect(pod.Spec.Volumes).To(HaveLen(1))
+		Expect(pod.Spec.Volumes[0].Name).To(Equal("scratch"))
+		Expect(pod.Spec.Volumes[0].EmptyDir.Medium).To(Equal(corev1.StorageMediumMemory))
+		Expect(pod.Spec.Volumes[0].EmptyDir.SizeLimit).To(Equal(&sizeLimit))
+
+		expectedMounts := []corev1.VolumeMount{{Name: "scratch", MountPath: "/scratch"}}
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(Equal(expectedMounts))
+		Expect(pod.Spec.Containers[1].VolumeMounts).To(Equal(expectedMounts))
+		Expect(pod.Spec.InitContainers[0].VolumeMounts).To(Equal(expectedMounts))
+	})
+
+	It("should mount an existing volume into the containers lacking it", func() {
+		InjectPluginVolume(pod)
+		Expect(InjectPluginVolumeWithOptions(pod, PluginVolumeOptions{ExtraContainers: []string{"sidecar"}})).
+			To(Succeed())
+
+		Expect(pod.Spec.Volumes).To(HaveLen(1))
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(HaveLen(1))
+		Expect(pod.Spec.Containers[1].VolumeMounts).To(Equal([]corev1.VolumeMount{
+			{Name: pluginVolumeName, MountPath: pluginMountPath},
+		}))
+	})
+
+	It("should refuse to change the medium or size limit of an existing volume", func() {
+		InjectPluginVolume(pod)
+		original := pod.DeepCopy()
+
+		sizeLimit := resource.MustParse("64Mi")
+		Expect(InjectPluginVolumeWithOptions(pod, PluginVolumeOptions{Medium: corev1.StorageMediumMemory})).
+			To(MatchError(ErrPluginVolumeConflict))
+		Expect(InjectPluginVolumeWithOptions(pod, PluginVolumeOptions{SizeLimit: &sizeLimit})).
+			To(MatchError(ErrPluginVolumeConflict))
+		Expect(pod).To(Equal(original))
+	})
+})
+
 var _ = Describe("InjectPluginSidecar", func() {
 	var sidecar *corev1.Container
 
@@ -250,3 +327,317 @@ var _ = Describe("InjectPluginSidecarInitContainer", func() {
 		})
 	})
 })
+
+var _ = Describe("InjectPluginSidecarInitContainerWithOptions", func() {
+	var pod *corev1.Pod
+
+	BeforeEach(func() {
+		pod = &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: postgresContainerName,
+						VolumeMounts: []corev1.VolumeMount{
+							{Name: "pgdata", MountPath: "/var/lib/postgresql/data"},
+							{Name: "scratch-data", MountPath: "/run"},
+							{Name: "shm", MountPath: "/dev/shm"},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("should only propagate the selected volume mounts", func() {
+		sidecar := &corev1.Container{Name: "wal-archiver"}
+		err := InjectPluginSidecarInitContainerWithOptions(pod, sidecar, SidecarOptions{
+			PostgresVolumeMounts: PostgresVolumeMountSelector{
+				{VolumeName: "pgdata", ReadOnly: ptr.To(true)},
+				{VolumeName: "scratch-data"},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(pod.Spec.InitContainers).To(HaveLen(1))
+		Expect(pod.Spec.InitContainers[0].VolumeMounts).To(Equal([]corev1.VolumeMount{
+			{Name: "pgdata", MountPath: "/var/lib/postgresql/data", ReadOnly: true},
+			{Name: "scratch-data", MountPath: "/run"},
+		}))
+		Expect(sidecar.VolumeMounts).To(BeEmpty())
+	})
+
+	It("should not propagate volume mounts conflicting with the sidecar ones", func() {
+		sidecar := &corev1.Container{
+			Name:         "wal-archiver",
+			VolumeMounts: []corev1.VolumeMount{{Name: "own", MountPath: "/run"}},
+		}
+		err := InjectPluginSidecarInitContainerWithOptions(pod, sidecar, SidecarOptions{
+			PostgresVolumeMounts: AllPostgresVolumeMounts,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(pod.Spec.InitContainers[0].VolumeMounts).To(Equal([]corev1.VolumeMount{
+			{Name: "own", MountPath: "/run"},
+			{Name: "pgdata", MountPath: "/var/lib/postgresql/data"},
+			{Name: "shm", MountPath: "/dev/shm"},
+			{Name: pluginVolumeName, MountPath: pluginMountPath},
+		}))
+	})
+
+	It("should fail for nil pods", func() {
+		err := InjectPluginSidecarInitContainerWithOptions(nil, &corev1.Container{}, SidecarOptions{})
+		Expect(err).To(MatchError(ErrNilPodPassed))
+	})
+})
+
+var _ = Describe("ReconcilePluginSidecarInitContainer", func() {
+	var (
+		pod     *corev1.Pod
+		sidecar *corev1.Container
+		options SidecarOptions
+	)
+
+	BeforeEach(func() {
+		pod = &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:         postgresContainerName,
+						VolumeMounts: []corev1.VolumeMount{{Name: "pgdata", MountPath: "/var/lib/postgresql/data"}},
+					},
+				},
+			},
+		}
+		sidecar = &corev1.Container{
+			Name:  "plugin",
+			Image: "plugin:1",
+			Args:  []string{"run"},
+		}
+		options = SidecarOptions{
+			PostgresVolumeMounts: PostgresVolumeMountSelector{{VolumeName: "pgdata"}},
+		}
+	})
+
+	It("should inject a missing sidecar and report the change", func() {
+		changed, err := ReconcilePluginSidecarInitContainer(pod, sidecar, options)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(pod.Spec.InitContainers).To(HaveLen(1))
+		Expect(pod.Spec.InitContainers[0].Image).To(Equal("plugin:1"))
+	})
+
+	It("should report no change when the sidecar is up to date", func() {
+		_, err := ReconcilePluginSidecarInitContainer(pod, sidecar, options)
+		Expect(err).ToNot(HaveOccurred())
+
+		changed, err := ReconcilePluginSidecarInitContainer(pod, sidecar, options)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		Expect(pod.Spec.InitContainers).To(HaveLen(1))
+	})
+
+	It("should update an outdated sidecar in place", func() {
+		pod.Spec.InitContainers = []corev1.Container{
+			{Name: "bootstrap", Image: "bootstrap:1"},
+			{Name: "plugin", Image: "plugin:0", Args: []string{"old"}, WorkingDir: "/work"},
+		}
+		sidecar.Env = []corev1.EnvVar{{Name: "A", Value: "1"}}
+		sidecar.Resources = corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+		}
+
+		changed, err := ReconcilePluginSidecarInitContainer(pod, sidecar, options)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+
+		Expect(pod.Spec.InitContainers).To(HaveLen(2))
+		updatedSidecar := pod.Spec.InitContainers[1]
+		Expect(updatedSidecar.Image).To(Equal("plugin:1"))
+		Expect(updatedSidecar.Args).To(Equal([]string{"run"}))
+		Expect(updatedSidecar.Env).To(Equal(sidecar.Env))
+		Expect(updatedSidecar.Resources).To(Equal(sidecar.Resources))
+		Expect(updatedSidecar.VolumeMounts).To(Equal([]corev1.VolumeMount{
+			{Name: "pgdata", MountPath: "/var/lib/postgresql/data"},
+		}))
+		Expect(updatedSidecar.RestartPolicy).To(Equal(ptr.To(corev1.ContainerRestartPolicyAlways)))
+		Expect(updatedSidecar.WorkingDir).To(Equal("/work"))
+	})
+
+	It("should leave an outdated sidecar untouched when injecting", func() {
+		pod.Spec.InitContainers = []corev1.Container{{Name: "plugin", Image: "plugin:0"}}
+
+		Expect(InjectPluginSidecarInitContainerWithOptions(pod, sidecar, options)).To(Succeed())
+		Expect(pod.Spec.InitContainers[0].Image).To(Equal("plugin:0"))
+	})
+
+	It("should fail for Pods without the postgres container", func() {
+		pod.Spec.Containers = nil
+		changed, err := ReconcilePluginSidecarInitContainer(pod, sidecar, options)
+		Expect(err).To(MatchError(ErrNoPostgresContainerFound))
+		Expect(changed).To(BeFalse())
+	})
+
+	It("should fail for nil pods", func() {
+		_, err := ReconcilePluginSidecarInitContainer(nil, sidecar, options)
+		Expect(err).To(MatchError(ErrNilPodPassed))
+	})
+})
+
+var _ = Describe("Sidecar plugin volume", func() {
+	var (
+		pod     *corev1.Pod
+		sidecar *corev1.Container
+		options SidecarOptions
+	)
+
+	BeforeEach(func() {
+		pod = &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: postgresContainerName}},
+			},
+		}
+		sidecar = &corev1.Container{Name: "plugin", Image: "plugin:1"}
+
+		sizeLimit := resource.MustParse("64Mi")
+		options = SidecarOptions{
+			PluginVolume: PluginVolumeOptions{
+				Name:            "scratch",
+				MountPath:       "/scratch",
+				Medium:          corev1.StorageMediumMemory,
+				SizeLimit:       &sizeLimit,
+				ExtraContainers: []string{"plugin"},
+			},
+		}
+	})
+
+	It("should inject the configured volume and mount it into the sidecar", func() {
+		Expect(InjectPluginSidecarInitContainerWithOptions(pod, sidecar, options)).To(Succeed())
+
+		Expect(pod.Spec.Volumes).To(HaveLen(1))
+		Expect(pod.Spec.Volumes[0].Name).To(Equal("scratch"))
+		Expect(pod.Spec.Volumes[0].EmptyDir.Medium).To(Equal(corev1.StorageMediumMemory))
+		Expect(pod.Spec.Volumes[0].EmptyDir.SizeLimit).To(Equal(options.PluginVolume.SizeLimit))
+
+		expectedMounts := []corev1.VolumeMount{{Name: "scratch", MountPath: "/scratch"}}
+		Expect(pod.Spec.Containers[0].VolumeMounts).To(Equal(expectedMounts))
+		Expect(pod.Spec.InitContainers[0].VolumeMounts).To(Equal(expectedMounts))
+	})
+
+	It("should keep the sidecar mount when reconciling", func() {
+		Expect(InjectPluginSidecarInitContainerWithOptions(pod, sidecar, options)).To(Succeed())
+
+		changed, err := ReconcilePluginSidecarInitContainer(pod, sidecar, options)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		Expect(pod.Spec.InitContainers[0].VolumeMounts).To(HaveLen(1))
+	})
+
+	It("should fail when the volume exists with a different configuration", func() {
+		Expect(InjectPluginSidecarInitContainerWithOptions(pod, sidecar, SidecarOptions{
+			PluginVolume: PluginVolumeOptions{Name: "scratch"},
+		})).To(Succeed())
+
+		err := InjectPluginSidecarInitContainerWithOptions(pod, sidecar, options)
+		Expect(err).To(MatchError(ErrPluginVolumeConflict))
+	})
+})
+
+var _ = Describe("Sidecar placement", func() {
+	var (
+		pod     *corev1.Pod
+		sidecar *corev1.Container
+	)
+
+	initContainerNames := func() []string {
+		names := make([]string, 0, len(pod.Spec.InitContainers))
+		for _, container := range pod.Spec.InitContainers {
+			names = append(names, container.Name)
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		pod = &corev1.Pod{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "bootstrap"}, {Name: "other-plugin"}},
+				Containers:     []corev1.Container{{Name: postgresContainerName}},
+			},
+		}
+		sidecar = &corev1.Container{Name: "plugin", Image: "plugin:1"}
+	})
+
+	DescribeTable("injecting a new sidecar",
+		func(options SidecarOptions, expected []string) {
+			Expect(InjectPluginSidecarInitContainerWithOptions(pod, sidecar, options)).To(Succeed())
+			Expect(initContainerNames()).To(Equal(expected))
+		},
+		Entry("appends by default",
+			SidecarOptions{},
+			[]string{"bootstrap", "other-plugin", "plugin"}),
+		Entry("places it first",
+			SidecarOptions{Placement: PlaceFirst},
+			[]string{"plugin", "bootstrap", "other-plugin"}),
+		Entry("places it before a reference",
+			SidecarOptions{Placement: PlaceBefore, PlacementReference: "other-plugin"},
+			[]string{"bootstrap", "plugin", "other-plugin"}),
+		Entry("places it after a reference",
+			SidecarOptions{Placement: PlaceAfter, PlacementReference: "bootstrap"},
+			[]string{"bootstrap", "plugin", "other-plugin"}),
+	)
+
+	DescribeTable("rejecting invalid placements",
+		func(options SidecarOptions, expectedErr error) {
+			err := InjectPluginSidecarInitContainerWithOptions(pod, sidecar, options)
+			Expect(err).To(MatchError(expectedErr))
+			Expect(initContainerNames()).To(Equal([]string{"bootstrap", "other-plugin"}))
+			Expect(pod.Spec.Volumes).To(BeEmpty())
+		},
+		Entry("reference missing for before",
+			SidecarOptions{Placement: PlaceBefore}, ErrInvalidSidecarPlacement),
+		Entry("reference passed for first",
+			SidecarOptions{Placement: PlaceFirst, PlacementReference: "bootstrap"}, ErrInvalidSidecarPlacement),
+		Entry("reference to the sidecar itself",
+			SidecarOptions{Placement: PlaceAfter, PlacementReference: "plugin"}, ErrInvalidSidecarPlacement),
+		Entry("unknown placement",
+			SidecarOptions{Placement: SidecarPlacement(42)}, ErrInvalidSidecarPlacement),
+		Entry("reference not in the Pod",
+			SidecarOptions{Placement: PlaceAfter, PlacementReference: "missing"}, ErrSidecarReferenceNotFound),
+	)
+
+	It("should accept an existing sidecar already correctly placed", func() {
+		pod.Spec.InitContainers = []corev1.Container{{Name: "bootstrap"}, {Name: "plugin"}, {Name: "other-plugin"}}
+		options := SidecarOptions{Placement: PlaceBefore, PlacementReference: "other-plugin"}
+
+		Expect(InjectPluginSidecarInitContainerWithOptions(pod, sidecar, options)).To(Succeed())
+		Expect(initContainerNames()).To(Equal([]string{"bootstrap", "plugin", "other-plugin"}))
+	})
+
+	It("should fail when an existing sidecar is misplaced and not reconciled", func() {
+		pod.Spec.InitContainers = []corev1.Container{{Name: "plugin"}, {Name: "bootstrap"}, {Name: "other-plugin"}}
+		options := SidecarOptions{Placement: PlaceAfter, PlacementReference: "other-plugin"}
+
+		err := InjectPluginSidecarInitContainerWithOptions(pod, sidecar, options)
+		Expect(err).To(MatchError(ErrInconsistentSidecarOrder))
+	})
+
+	It("should move a misplaced sidecar when reconciling", func() {
+		pod.Spec.InitContainers = []corev1.Container{{Name: "plugin"}, {Name: "bootstrap"}, {Name: "other-plugin"}}
+		options := SidecarOptions{Placement: PlaceAfter, PlacementReference: "other-plugin"}
+
+		changed, err := ReconcilePluginSidecarInitContainer(pod, sidecar, options)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(initContainerNames()).To(Equal([]string{"bootstrap", "other-plugin", "plugin"}))
+		Expect(pod.Spec.InitContainers[2].Image).To(Equal("plugin:1"))
+	})
+
+	It("should not move a correctly placed sidecar when reconciling", func() {
+		options := SidecarOptions{Placement: PlaceFirst}
+		Expect(InjectPluginSidecarInitContainerWithOptions(pod, sidecar, options)).To(Succeed())
+
+		changed, err := ReconcilePluginSidecarInitContainer(pod, sidecar, options)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		Expect(initContainerNames()).To(Equal([]string{"plugin", "bootstrap", "other-plugin"}))
+	})
+})
@@ -0,0 +1,96 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PostgresVolumeMountRule selects some of the volume mounts of the
+// PostgreSQL container. A volume mount is selected when it matches
+// every criterion being set, so an empty rule selects every
+// volume mount.
+type PostgresVolumeMountRule struct {
+	// VolumeName selects the mounts of the volume having this name
+	VolumeName string
+
+	// MountPathPrefix selects the mounts whose path is, or is below,
+	// this path
+	MountPathPrefix string
+
+	// ReadOnly, when set, overrides the read-only flag of the
+	// selected volume mounts
+	ReadOnly *bool
+}
+
+func (r PostgresVolumeMountRule) matches(volumeMount corev1.VolumeMount) bool {
+	if r.VolumeName != "" && r.VolumeName != volumeMount.Name {
+		return false
+	}
+
+	if r.MountPathPrefix != "" {
+		prefix := strings.TrimSuffix(r.MountPathPrefix, "/")
+		if volumeMount.MountPath != prefix && !strings.HasPrefix(volumeMount.MountPath, prefix+"/") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// PostgresVolumeMountSelector selects the volume mounts of the
+// PostgreSQL container to be propagated to a sidecar, i.e.
+//
+//	object.PostgresVolumeMountSelector{
+//		{VolumeName: "pgdata", ReadOnly: ptr.To(true)},
+//		{VolumeName: "scratch-data"},
+//	}
+//
+// A volume mount is propagated when it is selected by at least one
+// rule, and the first selecting rule applies. A nil selector
+// propagates no volume mount.
+type PostgresVolumeMountSelector []PostgresVolumeMountRule
+
+// AllPostgresVolumeMounts selects every volume mount of the PostgreSQL
+// container, granting the sidecar superuser access to the instance.
+var AllPostgresVolumeMounts = PostgresVolumeMountSelector{{}}
+
+// Select returns the selected volume mounts among the passed ones.
+func (s PostgresVolumeMountSelector) Select(volumeMounts []corev1.VolumeMount) []corev1.VolumeMount {
+	var result []corev1.VolumeMount
+	for _, volumeMount := range volumeMounts {
+		for _, rule := range s {
+			if !rule.matches(volumeMount) {
+				continue
+			}
+
+			selectedVolumeMount := *volumeMount.DeepCopy()
+			if rule.ReadOnly != nil {
+				selectedVolumeMount.ReadOnly = *rule.ReadOnly
+			}
+			result = append(result, selectedVolumeMount)
+			break
+		}
+	}
+
+	return result
+}
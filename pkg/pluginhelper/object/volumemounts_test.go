@@ -0,0 +1,78 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package object
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PostgresVolumeMountSelector", func() {
+	volumeMounts := []corev1.VolumeMount{
+		{Name: "pgdata", MountPath: "/var/lib/postgresql/data"},
+		{Name: "scratch-data", MountPath: "/run"},
+		{Name: "shm", MountPath: "/dev/shm"},
+		{Name: "pgwal", MountPath: "/var/lib/postgresql/wal", ReadOnly: true},
+	}
+
+	It("should select every volume mount", func() {
+		Expect(AllPostgresVolumeMounts.Select(volumeMounts)).To(Equal(volumeMounts))
+	})
+
+	It("should select no volume mount when nil", func() {
+		Expect(PostgresVolumeMountSelector(nil).Select(volumeMounts)).To(BeEmpty())
+	})
+
+	It("should select the volume mounts by name overriding the read-only flag", func() {
+		selector := PostgresVolumeMountSelector{
+			{VolumeName: "pgdata", ReadOnly: ptr.To(true)},
+			{VolumeName: "scratch-data"},
+		}
+		Expect(selector.Select(volumeMounts)).To(Equal([]corev1.VolumeMount{
+			{Name: "pgdata", MountPath: "/var/lib/postgresql/data", ReadOnly: true},
+			{Name: "scratch-data", MountPath: "/run"},
+		}))
+	})
+
+	It("should select the volume mounts by path prefix", func() {
+		selector := PostgresVolumeMountSelector{
+			{MountPathPrefix: "/var/lib/postgresql/", ReadOnly: ptr.To(false)},
+			{MountPathPrefix: "/dev/sh"},
+		}
+		Expect(selector.Select(volumeMounts)).To(Equal([]corev1.VolumeMount{
+			{Name: "pgdata", MountPath: "/var/lib/postgresql/data"},
+			{Name: "pgwal", MountPath: "/var/lib/postgresql/wal"},
+		}))
+	})
+
+	It("should apply the first matching rule", func() {
+		selector := PostgresVolumeMountSelector{
+			{VolumeName: "pgwal"},
+			{MountPathPrefix: "/var/lib/postgresql", ReadOnly: ptr.To(false)},
+		}
+		Expect(selector.Select(volumeMounts)).To(Equal([]corev1.VolumeMount{
+			{Name: "pgdata", MountPath: "/var/lib/postgresql/data"},
+			{Name: "pgwal", MountPath: "/var/lib/postgresql/wal", ReadOnly: true},
+		}))
+	})
+})
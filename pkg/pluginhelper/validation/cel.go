@@ -0,0 +1,190 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/cloudnative-pg/cnpg-i/pkg/operator"
+	"github.com/google/cel-go/cel"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+)
+
+// CELRule is a validation rule written as a CEL expression. The
+// expression must evaluate to true when the configuration is valid,
+// and can use the following variables:
+//
+//   - `params`: the parameters of the plugin, as a map of strings
+//   - `cluster`: a map with the `name`, `namespace`, `labels`,
+//     `annotations`, `instances`, `imageName` and `postgresMajorVersion`
+//     fields of the Cluster, the latter being 0 when unknown
+//
+// i.e.
+//
+//	!has(params.compression) || params.compression != 'zstd' || int(params.level) <= 19
+type CELRule struct {
+	// Expression is the CEL expression to be evaluated
+	Expression string
+
+	// Message is the error reported when the rule is not satisfied
+	Message string
+
+	// Parameter is the name of the parameter the error is reported
+	// on. When empty, the error refers to the plugin configuration
+	Parameter string
+}
+
+// CELRuleSet is a set of compiled CEL rules.
+type CELRuleSet struct {
+	rules    []CELRule
+	programs []cel.Program
+}
+
+var (
+	celEnvOnce sync.Once
+	celEnv     *cel.Env
+	celEnvErr  error
+
+	// celProgramCache contains the compiled programs, indexed by expression
+	celProgramCache sync.Map
+)
+
+func getCELEnv() (*cel.Env, error) {
+	celEnvOnce.Do(func() {
+		celEnv, celEnvErr = cel.NewEnv(
+			cel.Variable("params", cel.MapType(cel.StringType, cel.StringType)),
+			cel.Variable("cluster", cel.MapType(cel.StringType, cel.DynType)),
+		)
+	})
+
+	return celEnv, celEnvErr
+}
+
+// NewCELRuleSet compiles the passed rules. Compiled programs are cached
+// and shared between rule sets using the same expressions.
+func NewCELRuleSet(rules ...CELRule) (*CELRuleSet, error) {
+	result := &CELRuleSet{
+		rules:    rules,
+		programs: make([]cel.Program, len(rules)),
+	}
+
+	for idx, rule := range rules {
+		program, err := compileCELExpression(rule.Expression)
+		if err != nil {
+			return nil, err
+		}
+		result.programs[idx] = program
+	}
+
+	return result, nil
+}
+
+func compileCELExpression(expression string) (cel.Program, error) {
+	if program, ok := celProgramCache.Load(expression); ok {
+		return program.(cel.Program), nil
+	}
+
+	env, err := getCELEnv()
+	if err != nil {
+		return nil, fmt.Errorf("while creating the CEL environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("while compiling CEL expression %q: %w", expression, issues.Err())
+	}
+
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf( //nolint:err113
+			"CEL expression %q must evaluate to a boolean, not %v", expression, ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("while creating the program for CEL expression %q: %w", expression, err)
+	}
+
+	celProgramCache.Store(expression, program)
+
+	return program, nil
+}
+
+// Validate evaluates the rules against the passed plugin, creating a
+// validation error for every rule that is not satisfied or that cannot
+// be evaluated.
+func (s *CELRuleSet) Validate(plugin *common.Plugin) []*operator.ValidationError {
+	activation := map[string]any{
+		"params":  buildCELParams(plugin),
+		"cluster": buildCELCluster(plugin),
+	}
+
+	var result []*operator.ValidationError
+	for idx, rule := range s.rules {
+		value, _, err := s.programs[idx].Eval(activation)
+
+		var message string
+		switch {
+		case err != nil:
+			message = fmt.Sprintf("%s (while evaluating rule: %v)", rule.Message, err)
+		case value.Value() != true:
+			message = rule.Message
+		default:
+			continue
+		}
+
+		if rule.Parameter != "" {
+			result = append(result, BuildErrorForParameter(plugin, rule.Parameter, message))
+		} else {
+			result = append(result, PluginPath(plugin).NewError("", message))
+		}
+	}
+
+	return result
+}
+
+func buildCELParams(plugin *common.Plugin) map[string]string {
+	if plugin.Parameters == nil {
+		return map[string]string{}
+	}
+
+	return plugin.Parameters
+}
+
+func buildCELCluster(plugin *common.Plugin) map[string]any {
+	if plugin.Cluster == nil {
+		return map[string]any{}
+	}
+
+	cluster := plugin.Cluster
+	majorVersion, _ := common.GetPostgresMajorVersion(cluster)
+
+	return map[string]any{
+		"name":                 cluster.Name,
+		"namespace":            cluster.Namespace,
+		"labels":               maps.Clone(cluster.Labels),
+		"annotations":          maps.Clone(cluster.Annotations),
+		"instances":            cluster.Spec.Instances,
+		"imageName":            cluster.Spec.ImageName,
+		"postgresMajorVersion": majorVersion,
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CELRuleSet", func() {
+	var cluster apiv1.Cluster
+
+	BeforeEach(func() {
+		cluster = apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "cluster-example",
+			},
+			Spec: apiv1.ClusterSpec{
+				Instances: 3,
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:17.2",
+				Plugins: []apiv1.PluginConfiguration{
+					{
+						Name: "test-plugin",
+						Parameters: map[string]string{
+							"compression": "zstd",
+							"level":       "22",
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("should report the rules that are not satisfied", func() {
+		ruleSet, err := NewCELRuleSet(
+			CELRule{
+				Expression: "params.compression != 'zstd' || int(params.level) <= 19",
+				Message:    "zstd compression level must be at most 19",
+				Parameter:  "level",
+			},
+			CELRule{
+				Expression: "cluster.instances >= 2 && cluster.postgresMajorVersion >= 16",
+				Message:    "unsupported cluster",
+			},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		errs := ruleSet.Validate(common.NewPlugin(cluster, "test-plugin"))
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].GetPathComponents()).To(Equal([]string{"spec", "plugins", "0", "level"}))
+		Expect(errs[0].GetValue()).To(Equal("22"))
+		Expect(errs[0].GetMessage()).To(Equal("zstd compression level must be at most 19"))
+	})
+
+	It("should report errors on the plugin configuration when no parameter is specified", func() {
+		ruleSet, err := NewCELRuleSet(CELRule{
+			Expression: "cluster.name.startsWith('pg-') && size(cluster.labels) == 0",
+			Message:    "the cluster name must start with pg-",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		errs := ruleSet.Validate(common.NewPlugin(cluster, "test-plugin"))
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].GetPathComponents()).To(Equal([]string{"spec", "plugins", "0"}))
+	})
+
+	It("should report the rules that cannot be evaluated", func() {
+		ruleSet, err := NewCELRuleSet(CELRule{
+			Expression: "params.missing == 'value'",
+			Message:    "missing must be set to value",
+			Parameter:  "missing",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		errs := ruleSet.Validate(common.NewPlugin(cluster, "test-plugin"))
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].GetMessage()).To(ContainSubstring("while evaluating rule"))
+	})
+
+	It("should accept plugins that satisfy every rule", func() {
+		ruleSet, err := NewCELRuleSet(CELRule{
+			Expression: "!has(params.missing) && params.compression in ['gzip', 'zstd']",
+			Message:    "unsupported compression",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ruleSet.Validate(common.NewPlugin(cluster, "test-plugin"))).To(BeEmpty())
+		Expect(ruleSet.Validate(&common.Plugin{PluginIndex: -1})).ToNot(BeEmpty())
+	})
+
+	It("should reuse the compiled programs", func() {
+		rule := CELRule{Expression: "size(params) > 0", Message: "no parameters"}
+		first, err := NewCELRuleSet(rule)
+		Expect(err).ToNot(HaveOccurred())
+		second, err := NewCELRuleSet(rule)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(first.programs[0]).To(BeIdenticalTo(second.programs[0]))
+	})
+
+	It("should refuse invalid expressions", func() {
+		_, err := NewCELRuleSet(CELRule{Expression: "params.level <="})
+		Expect(err).To(HaveOccurred())
+
+		_, err = NewCELRuleSet(CELRule{Expression: "params.level"})
+		Expect(err).To(MatchError(ContainSubstring("must evaluate to a boolean")))
+	})
+})
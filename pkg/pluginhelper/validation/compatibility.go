@@ -0,0 +1,166 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	"github.com/cloudnative-pg/cnpg-i/pkg/operator"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+)
+
+// Compatibility is the compatibility matrix of a plugin, declaring the
+// PostgreSQL major versions and the image catalogs it supports.
+type Compatibility struct {
+	// MinMajorVersion is the minimum supported PostgreSQL major version.
+	// Zero means no lower bound
+	MinMajorVersion int
+
+	// MaxMajorVersion is the maximum supported PostgreSQL major version.
+	// Zero means no upper bound
+	MaxMajorVersion int
+
+	// UnsupportedMajorVersions are the PostgreSQL major versions that are
+	// not supported, even if they are inside the declared bounds
+	UnsupportedMajorVersions []int
+
+	// ImageCatalogs are the supported image catalogs.
+	// When empty, every image catalog is supported
+	ImageCatalogs []ImageCatalog
+
+	// RejectUnknownMajorVersion makes the check fail when the PostgreSQL
+	// major version cannot be detected, i.e. when the Cluster is using
+	// the default image of the operator. By default, such Clusters
+	// are accepted
+	RejectUnknownMajorVersion bool
+}
+
+// ImageCatalog identifies an image catalog.
+type ImageCatalog struct {
+	// Kind is the kind of the image catalog, either ImageCatalog
+	// or ClusterImageCatalog
+	Kind string
+
+	// Name is the name of the image catalog
+	Name string
+}
+
+// String implements the fmt.Stringer interface.
+func (c ImageCatalog) String() string {
+	return c.Kind + "/" + c.Name
+}
+
+// ValidateClusterCreate checks that the Cluster of the passed plugin is
+// compatible with the plugin.
+func (c Compatibility) ValidateClusterCreate(plugin *common.Plugin) []*operator.ValidationError {
+	return c.validateCluster(plugin.Cluster)
+}
+
+// ValidateClusterChange checks that the updated Cluster is compatible with the
+// plugin. Nothing is reported when neither the PostgreSQL image nor the
+// image catalog have been changed, to avoid blocking unrelated updates.
+func (c Compatibility) ValidateClusterChange(oldPlugin, newPlugin *common.Plugin) []*operator.ValidationError {
+	oldCluster, newCluster := oldPlugin.Cluster, newPlugin.Cluster
+	if oldCluster.Spec.ImageName == newCluster.Spec.ImageName &&
+		imageCatalogRefEqual(oldCluster.Spec.ImageCatalogRef, newCluster.Spec.ImageCatalogRef) {
+		return nil
+	}
+
+	return c.validateCluster(newCluster)
+}
+
+func (c Compatibility) validateCluster(cluster *apiv1.Cluster) []*operator.ValidationError {
+	var result []*operator.ValidationError
+
+	versionPath := SpecPath().Child("imageName")
+	versionValue := cluster.Spec.ImageName
+	if catalogRef := cluster.Spec.ImageCatalogRef; catalogRef != nil {
+		versionPath = SpecPath().Child("imageCatalogRef", "major")
+		versionValue = strconv.Itoa(catalogRef.Major)
+
+		catalog := ImageCatalog{Kind: catalogRef.Kind, Name: catalogRef.Name}
+		if len(c.ImageCatalogs) > 0 && !slices.Contains(c.ImageCatalogs, catalog) {
+			result = append(result, SpecPath().Child("imageCatalogRef", "name").NewError(
+				catalogRef.Name,
+				fmt.Sprintf("unsupported image catalog, the plugin supports: %v", c.ImageCatalogs),
+			))
+		}
+	}
+
+	majorVersion, err := common.GetPostgresMajorVersion(cluster)
+	switch {
+	case err != nil && c.RejectUnknownMajorVersion:
+		result = append(result, versionPath.NewError(versionValue, err.Error()))
+
+	case err == nil && !c.IsMajorVersionSupported(majorVersion):
+		result = append(result, versionPath.NewError(
+			versionValue,
+			fmt.Sprintf("PostgreSQL %d is not supported by the plugin, %s", majorVersion, c.describeVersions()),
+		))
+	}
+
+	return result
+}
+
+// IsMajorVersionSupported checks if the passed PostgreSQL major version
+// is supported.
+func (c Compatibility) IsMajorVersionSupported(majorVersion int) bool {
+	if c.MinMajorVersion > 0 && majorVersion < c.MinMajorVersion {
+		return false
+	}
+
+	if c.MaxMajorVersion > 0 && majorVersion > c.MaxMajorVersion {
+		return false
+	}
+
+	return !slices.Contains(c.UnsupportedMajorVersions, majorVersion)
+}
+
+func (c Compatibility) describeVersions() string {
+	result := "supported versions are"
+	switch {
+	case c.MinMajorVersion > 0 && c.MaxMajorVersion > 0:
+		result += fmt.Sprintf(" %d to %d", c.MinMajorVersion, c.MaxMajorVersion)
+	case c.MinMajorVersion > 0:
+		result += fmt.Sprintf(" %d and newer", c.MinMajorVersion)
+	case c.MaxMajorVersion > 0:
+		result += fmt.Sprintf(" up to %d", c.MaxMajorVersion)
+	default:
+		result += " all"
+	}
+
+	if len(c.UnsupportedMajorVersions) > 0 {
+		result += fmt.Sprintf(" except %v", c.UnsupportedMajorVersions)
+	}
+
+	return result
+}
+
+func imageCatalogRefEqual(a, b *apiv1.ImageCatalogRef) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Major == b.Major && a.Name == b.Name && a.Kind == b.Kind
+}
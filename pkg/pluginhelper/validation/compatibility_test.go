@@ -0,0 +1,134 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compatibility", func() {
+	compatibility := Compatibility{
+		MinMajorVersion:          14,
+		MaxMajorVersion:          18,
+		UnsupportedMajorVersions: []int{15},
+		ImageCatalogs: []ImageCatalog{
+			{Kind: apiv1.ClusterImageCatalogKind, Name: "postgresql"},
+		},
+	}
+
+	newPlugin := func(spec apiv1.ClusterSpec) *common.Plugin {
+		return common.NewPlugin(apiv1.Cluster{Spec: spec}, "test-plugin")
+	}
+
+	DescribeTable(
+		"IsMajorVersionSupported",
+		func(majorVersion int, expected bool) {
+			Expect(compatibility.IsMajorVersionSupported(majorVersion)).To(Equal(expected))
+		},
+		Entry("below the lower bound", 13, false),
+		Entry("at the lower bound", 14, true),
+		Entry("explicitly unsupported", 15, false),
+		Entry("at the upper bound", 18, true),
+		Entry("above the upper bound", 19, false),
+	)
+
+	It("should accept supported image names", func() {
+		plugin := newPlugin(apiv1.ClusterSpec{ImageName: "ghcr.io/cloudnative-pg/postgresql:17.2"})
+		Expect(compatibility.ValidateClusterCreate(plugin)).To(BeEmpty())
+	})
+
+	It("should refuse unsupported image names", func() {
+		plugin := newPlugin(apiv1.ClusterSpec{ImageName: "ghcr.io/cloudnative-pg/postgresql:13.2"})
+		errs := compatibility.ValidateClusterCreate(plugin)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].GetPathComponents()).To(Equal([]string{"spec", "imageName"}))
+		Expect(errs[0].GetValue()).To(Equal("ghcr.io/cloudnative-pg/postgresql:13.2"))
+		Expect(errs[0].GetMessage()).To(ContainSubstring("14 to 18 except [15]"))
+	})
+
+	It("should check the image catalog and its major version", func() {
+		plugin := newPlugin(apiv1.ClusterSpec{
+			ImageCatalogRef: &apiv1.ImageCatalogRef{
+				TypedLocalObjectReference: corev1.TypedLocalObjectReference{
+					Kind: "ImageCatalog",
+					Name: "custom",
+				},
+				Major: 15,
+			},
+		})
+
+		errs := compatibility.ValidateClusterCreate(plugin)
+		Expect(errs).To(HaveLen(2))
+		Expect(errs[0].GetPathComponents()).To(Equal([]string{"spec", "imageCatalogRef", "name"}))
+		Expect(errs[1].GetPathComponents()).To(Equal([]string{"spec", "imageCatalogRef", "major"}))
+		Expect(errs[1].GetValue()).To(Equal("15"))
+	})
+
+	It("should distinguish image catalogs by kind", func() {
+		newCatalogPlugin := func(kind string) *common.Plugin {
+			return newPlugin(apiv1.ClusterSpec{
+				ImageCatalogRef: &apiv1.ImageCatalogRef{
+					TypedLocalObjectReference: corev1.TypedLocalObjectReference{
+						Kind: kind,
+						Name: "postgresql",
+					},
+					Major: 17,
+				},
+			})
+		}
+
+		Expect(compatibility.ValidateClusterCreate(newCatalogPlugin(apiv1.ClusterImageCatalogKind))).To(BeEmpty())
+
+		errs := compatibility.ValidateClusterCreate(newCatalogPlugin(apiv1.ImageCatalogKind))
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].GetPathComponents()).To(Equal([]string{"spec", "imageCatalogRef", "name"}))
+		Expect(errs[0].GetMessage()).To(ContainSubstring("ClusterImageCatalog/postgresql"))
+	})
+
+	It("should accept clusters using the default operator image", func() {
+		plugin := newPlugin(apiv1.ClusterSpec{})
+		Expect(compatibility.ValidateClusterCreate(plugin)).To(BeEmpty())
+	})
+
+	It("should reject clusters whose version cannot be detected when requested", func() {
+		plugin := newPlugin(apiv1.ClusterSpec{})
+
+		strict := compatibility
+		strict.RejectUnknownMajorVersion = true
+		errs := strict.ValidateClusterCreate(plugin)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].GetPathComponents()).To(Equal([]string{"spec", "imageName"}))
+	})
+
+	It("should only check changes to the image", func() {
+		oldPlugin := newPlugin(apiv1.ClusterSpec{ImageName: "postgresql:13", Instances: 1})
+		updatedPlugin := newPlugin(apiv1.ClusterSpec{ImageName: "postgresql:13", Instances: 3})
+		Expect(compatibility.ValidateClusterChange(oldPlugin, updatedPlugin)).To(BeEmpty())
+
+		updatedPlugin = newPlugin(apiv1.ClusterSpec{ImageName: "postgresql:19"})
+		Expect(compatibility.ValidateClusterChange(oldPlugin, updatedPlugin)).To(HaveLen(1))
+	})
+})
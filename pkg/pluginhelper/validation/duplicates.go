@@ -0,0 +1,61 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	"fmt"
+	"strconv"
+
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	"github.com/cloudnative-pg/cnpg-i/pkg/operator"
+)
+
+// ValidateNoDuplicatePlugin checks that the passed plugin is configured
+// at most once in the `.spec.plugins` stanza of the Cluster, creating a
+// validation error for every duplicated entry.
+func ValidateNoDuplicatePlugin(cluster *apiv1.Cluster, pluginName string) []*operator.ValidationError {
+	var result []*operator.ValidationError
+
+	firstIndex := -1
+	for idx, cfg := range cluster.Spec.Plugins {
+		if cfg.Name != pluginName {
+			continue
+		}
+
+		if firstIndex == -1 {
+			firstIndex = idx
+			continue
+		}
+
+		result = append(result, &operator.ValidationError{
+			PathComponents: []string{
+				"spec",
+				"plugins",
+				strconv.Itoa(idx),
+				"name",
+			},
+			Message: fmt.Sprintf(
+				"plugin %q is already configured at index %d", pluginName, firstIndex),
+			Value: pluginName,
+		})
+	}
+
+	return result
+}
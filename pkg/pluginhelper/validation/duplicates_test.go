@@ -0,0 +1,61 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateNoDuplicatePlugin", func() {
+	It("should not report anything when the plugin is configured once", func() {
+		cluster := &apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				Plugins: []apiv1.PluginConfiguration{
+					{Name: "plugin1"},
+					{Name: "plugin2"},
+				},
+			},
+		}
+		Expect(ValidateNoDuplicatePlugin(cluster, "plugin1")).To(BeEmpty())
+	})
+
+	It("should report every duplicated entry", func() {
+		cluster := &apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				Plugins: []apiv1.PluginConfiguration{
+					{Name: "plugin2"},
+					{Name: "plugin1"},
+					{Name: "plugin1"},
+					{Name: "plugin1"},
+				},
+			},
+		}
+
+		errs := ValidateNoDuplicatePlugin(cluster, "plugin1")
+		Expect(errs).To(HaveLen(2))
+		Expect(errs[0].GetPathComponents()).To(Equal([]string{"spec", "plugins", "2", "name"}))
+		Expect(errs[0].GetValue()).To(Equal("plugin1"))
+		Expect(errs[0].GetMessage()).To(ContainSubstring("index 1"))
+		Expect(errs[1].GetPathComponents()).To(Equal([]string{"spec", "plugins", "3", "name"}))
+	})
+})
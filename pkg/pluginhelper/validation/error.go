@@ -20,8 +20,6 @@ SPDX-License-Identifier: Apache-2.0
 package validation
 
 import (
-	"strconv"
-
 	"github.com/cloudnative-pg/cnpg-i/pkg/operator"
 
 	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
@@ -30,25 +28,5 @@ import (
 // BuildErrorForParameter creates a validation error for a certain plugin
 // parameter.
 func BuildErrorForParameter(plugin *common.Plugin, name, message string) *operator.ValidationError {
-	if plugin.PluginIndex == -1 {
-		return &operator.ValidationError{
-			PathComponents: []string{
-				"spec",
-				"plugins",
-				name,
-			},
-			Message: message,
-		}
-	}
-
-	return &operator.ValidationError{
-		PathComponents: []string{
-			"spec",
-			"plugins",
-			strconv.Itoa(plugin.PluginIndex),
-			name,
-		},
-		Message: message,
-		Value:   plugin.Parameters[name],
-	}
+	return ParameterPath(plugin, name).NewError(plugin.Parameters[name], message)
 }
@@ -0,0 +1,66 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	"github.com/cloudnative-pg/cnpg-i/pkg/operator"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+)
+
+// ParameterValidator checks the value of a plugin parameter, returning
+// an error with a user-friendly message when it is not valid.
+type ParameterValidator func(value string) error
+
+// ValidateParameter checks the value of an optional plugin parameter
+// with the passed validators, stopping at the first failure. Nothing
+// is checked when the parameter is not set.
+func ValidateParameter(
+	plugin *common.Plugin,
+	name string,
+	validators ...ParameterValidator,
+) *operator.ValidationError {
+	value, ok := plugin.Parameters[name]
+	if !ok {
+		return nil
+	}
+
+	for _, validator := range validators {
+		if err := validator(value); err != nil {
+			return BuildErrorForParameter(plugin, name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// ValidateRequiredParameter checks that a plugin parameter is set to
+// a non-empty value, and then applies the passed validators.
+func ValidateRequiredParameter(
+	plugin *common.Plugin,
+	name string,
+	validators ...ParameterValidator,
+) *operator.ValidationError {
+	if plugin.Parameters[name] == "" {
+		return BuildErrorForParameter(plugin, name, "required parameter")
+	}
+
+	return ValidateParameter(plugin, name, validators...)
+}
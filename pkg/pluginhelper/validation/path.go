@@ -0,0 +1,272 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cloudnative-pg/cnpg-i/pkg/operator"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+)
+
+// Path represents the path to a field inside a Cluster definition,
+// and can be rendered as the PathComponents of a ValidationError.
+// It mimics the field.Path type of the Kubernetes apimachinery, i.e.
+//
+//	NewPath("spec", "postgresql", "parameters").Key("shared_buffers")
+type Path struct {
+	parent    *Path
+	name      string
+	subscript bool
+}
+
+// NewPath creates a root Path object.
+func NewPath(name string, moreNames ...string) *Path {
+	result := &Path{name: name}
+	for _, anotherName := range moreNames {
+		result = &Path{parent: result, name: anotherName}
+	}
+
+	return result
+}
+
+// SpecPath creates a Path pointing to the `.spec` stanza of the Cluster.
+func SpecPath() *Path {
+	return NewPath("spec")
+}
+
+// PluginPath creates a Path pointing to the configuration entry of the
+// passed plugin, taking into account where it has been read from. For
+// plugins read from a Backup or a ScheduledBackup, the Path refers to
+// that object rather than to the Cluster.
+func PluginPath(plugin *common.Plugin) *Path {
+	switch {
+	case plugin.Source == common.SourceBackup || plugin.Source == common.SourceScheduledBackup:
+		return SpecPath().Child("pluginConfiguration")
+
+	case plugin.Source == common.SourceExternalCluster:
+		return SpecPath().Child("externalClusters").Index(plugin.ExternalClusterIndex).Child("plugin")
+
+	case plugin.PluginIndex != -1:
+		return SpecPath().Child("plugins").Index(plugin.PluginIndex)
+
+	default:
+		return SpecPath().Child("plugins")
+	}
+}
+
+// ParameterPath creates a Path pointing to a parameter of the passed plugin,
+// taking into account where its configuration has been read from.
+// Parameters of plugins read from a Backup or a ScheduledBackup always
+// refer to that object, even when inherited from the Cluster, as it is
+// the object being validated.
+func ParameterPath(plugin *common.Plugin, name string) *Path {
+	switch plugin.Source {
+	case common.SourceBackup, common.SourceScheduledBackup, common.SourceExternalCluster:
+		return PluginPath(plugin).Child("parameters").Key(name)
+
+	default:
+		return PluginPath(plugin).Key(name)
+	}
+}
+
+// Child returns a new Path that is a child of this one.
+func (p *Path) Child(name string, moreNames ...string) *Path {
+	result := &Path{parent: p, name: name}
+	for _, anotherName := range moreNames {
+		result = &Path{parent: result, name: anotherName}
+	}
+
+	return result
+}
+
+// Index returns a new Path indexing this one, for lists.
+func (p *Path) Index(index int) *Path {
+	return &Path{parent: p, name: strconv.Itoa(index), subscript: true}
+}
+
+// Key returns a new Path subscripting this one, for maps.
+func (p *Path) Key(key string) *Path {
+	return &Path{parent: p, name: key, subscript: true}
+}
+
+// PathComponents returns the components of this Path, in the format
+// expected by the ValidationError type.
+func (p *Path) PathComponents() []string {
+	var result []string
+	for current := p; current != nil; current = current.parent {
+		result = append(result, current.name)
+	}
+	slices.Reverse(result)
+
+	return result
+}
+
+// FieldPath converts this Path to the equivalent apimachinery one.
+func (p *Path) FieldPath() *field.Path {
+	if p == nil {
+		return nil
+	}
+
+	parent := p.parent.FieldPath()
+	switch {
+	case parent == nil:
+		return field.NewPath(p.name)
+	case p.subscript:
+		return parent.Key(p.name)
+	default:
+		return parent.Child(p.name)
+	}
+}
+
+// String renders the Path using the apimachinery syntax, i.e.
+// `spec.plugins[0].name`.
+func (p *Path) String() string {
+	return p.FieldPath().String()
+}
+
+// NewError creates a validation error for the passed Path.
+func (p *Path) NewError(value, message string) *operator.ValidationError {
+	return &operator.ValidationError{
+		PathComponents: p.PathComponents(),
+		Value:          value,
+		Message:        message,
+	}
+}
+
+// NewPathFromComponents creates a Path from the components of a
+// ValidationError. Numeric components are considered list indexes.
+func NewPathFromComponents(components []string) *Path {
+	var result *Path
+	for _, component := range components {
+		_, err := strconv.Atoi(component)
+		result = &Path{parent: result, name: component, subscript: err == nil && result != nil}
+	}
+
+	return result
+}
+
+// NewPathFromFieldPath converts an apimachinery path into a Path.
+func NewPathFromFieldPath(fieldPath *field.Path) *Path {
+	if fieldPath == nil {
+		return nil
+	}
+
+	return parseFieldPath(fieldPath.String())
+}
+
+// FromFieldError converts an apimachinery validation error into the
+// CNPG-I format.
+func FromFieldError(err *field.Error) *operator.ValidationError {
+	message := err.Detail
+	if message == "" {
+		message = err.Type.String()
+	}
+
+	return &operator.ValidationError{
+		PathComponents: parseFieldPath(err.Field).PathComponents(),
+		Value:          formatBadValue(err.BadValue),
+		Message:        message,
+	}
+}
+
+// FromFieldErrorList converts a list of apimachinery validation errors
+// into the CNPG-I format.
+func FromFieldErrorList(list field.ErrorList) []*operator.ValidationError {
+	result := make([]*operator.ValidationError, 0, len(list))
+	for _, err := range list {
+		result = append(result, FromFieldError(err))
+	}
+
+	return result
+}
+
+// ToFieldErrorList converts a list of CNPG-I validation errors into
+// apimachinery ones, using the Invalid error type.
+func ToFieldErrorList(errs []*operator.ValidationError) field.ErrorList {
+	result := make(field.ErrorList, 0, len(errs))
+	for _, err := range errs {
+		result = append(result, field.Invalid(
+			NewPathFromComponents(err.GetPathComponents()).FieldPath(),
+			err.GetValue(),
+			err.GetMessage(),
+		))
+	}
+
+	return result
+}
+
+// parseFieldPath parses the string representation of an apimachinery
+// path, i.e. `spec.postgresql.parameters[shared_buffers]`.
+func parseFieldPath(fieldPath string) *Path {
+	var result *Path
+	var current strings.Builder
+	inSubscript := false
+
+	flush := func(subscript bool) {
+		if current.Len() > 0 || subscript {
+			result = &Path{parent: result, name: current.String(), subscript: subscript}
+		}
+		current.Reset()
+	}
+
+	for _, r := range fieldPath {
+		switch {
+		case inSubscript && r == ']':
+			flush(true)
+			inSubscript = false
+		case inSubscript:
+			current.WriteRune(r)
+		case r == '.':
+			flush(false)
+		case r == '[':
+			flush(false)
+			inSubscript = true
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush(false)
+
+	return result
+}
+
+func formatBadValue(value any) string {
+	switch v := value.(type) {
+	case nil, field.OmitValueType:
+		return ""
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	if data, err := json.Marshal(value); err == nil {
+		return string(data)
+	}
+
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	"github.com/cloudnative-pg/cnpg-i/pkg/operator"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path", func() {
+	It("should build the path components of any Cluster location", func() {
+		path := SpecPath().Child("postgresql", "parameters").Key("shared_buffers")
+		Expect(path.PathComponents()).To(Equal([]string{"spec", "postgresql", "parameters", "shared_buffers"}))
+		Expect(path.String()).To(Equal("spec.postgresql.parameters[shared_buffers]"))
+
+		path = NewPath("spec", "storage").Child("size")
+		Expect(path.PathComponents()).To(Equal([]string{"spec", "storage", "size"}))
+		Expect(path.String()).To(Equal("spec.storage.size"))
+	})
+
+	It("should convert from and to apimachinery paths", func() {
+		fieldPath := field.NewPath("spec", "plugins").Index(2).Child("parameters").Key("a.b[c")
+		path := NewPathFromFieldPath(fieldPath)
+		Expect(path.PathComponents()).To(Equal([]string{"spec", "plugins", "2", "parameters", "a.b[c"}))
+		Expect(path.FieldPath().String()).To(Equal(fieldPath.String()))
+		Expect(NewPathFromFieldPath(nil)).To(BeNil())
+	})
+
+	It("should create paths from validation error components", func() {
+		path := NewPathFromComponents([]string{"spec", "plugins", "0", "name"})
+		Expect(path.String()).To(Equal("spec.plugins[0].name"))
+	})
+
+	It("should create validation errors", func() {
+		err := SpecPath().Child("backup", "target").NewError("primary", "unsupported")
+		Expect(err.GetPathComponents()).To(Equal([]string{"spec", "backup", "target"}))
+		Expect(err.GetValue()).To(Equal("primary"))
+		Expect(err.GetMessage()).To(Equal("unsupported"))
+	})
+})
+
+var _ = Describe("ParameterPath", func() {
+	It("should point to the parameter of the Cluster plugin", func() {
+		plugin := &common.Plugin{PluginIndex: 1}
+		Expect(ParameterPath(plugin, "param").PathComponents()).To(
+			Equal([]string{"spec", "plugins", "1", "param"}))
+	})
+
+	It("should point to the plugins stanza when the plugin is not configured", func() {
+		plugin := &common.Plugin{PluginIndex: -1}
+		Expect(ParameterPath(plugin, "param").PathComponents()).To(
+			Equal([]string{"spec", "plugins", "param"}))
+	})
+
+	DescribeTable("should point to the backup plugin configuration parameters",
+		func(newPlugin func(apiv1.Cluster) *common.Plugin) {
+			cluster := apiv1.Cluster{
+				Spec: apiv1.ClusterSpec{
+					Plugins: []apiv1.PluginConfiguration{
+						{Name: "other-plugin"},
+						{Name: "test-plugin", Parameters: map[string]string{"inherited": "cluster"}},
+					},
+				},
+			}
+			plugin := newPlugin(cluster)
+
+			Expect(PluginPath(plugin).PathComponents()).To(
+				Equal([]string{"spec", "pluginConfiguration"}))
+
+			validationErr := BuildErrorForParameter(plugin, "param", "invalid")
+			Expect(validationErr.GetPathComponents()).To(
+				Equal([]string{"spec", "pluginConfiguration", "parameters", "param"}))
+			Expect(validationErr.GetValue()).To(Equal("backup"))
+
+			validationErr = BuildErrorForParameter(plugin, "inherited", "invalid")
+			Expect(validationErr.GetPathComponents()).To(
+				Equal([]string{"spec", "pluginConfiguration", "parameters", "inherited"}))
+			Expect(validationErr.GetValue()).To(Equal("cluster"))
+		},
+		Entry("Backup", func(cluster apiv1.Cluster) *common.Plugin {
+			backup := apiv1.Backup{
+				Spec: apiv1.BackupSpec{
+					PluginConfiguration: &apiv1.BackupPluginConfiguration{
+						Name:       "test-plugin",
+						Parameters: map[string]string{"param": "backup"},
+					},
+				},
+			}
+			return common.NewPluginFromBackup(cluster, backup, "test-plugin")
+		}),
+		Entry("ScheduledBackup", func(cluster apiv1.Cluster) *common.Plugin {
+			scheduledBackup := apiv1.ScheduledBackup{
+				Spec: apiv1.ScheduledBackupSpec{
+					PluginConfiguration: &apiv1.BackupPluginConfiguration{
+						Name:       "test-plugin",
+						Parameters: map[string]string{"param": "backup"},
+					},
+				},
+			}
+			return common.NewPluginFromScheduledBackup(cluster, scheduledBackup, "test-plugin")
+		}),
+	)
+
+	It("should point to the external cluster plugin parameters", func() {
+		cluster := apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				ExternalClusters: []apiv1.ExternalCluster{
+					{
+						Name: "origin",
+						PluginConfiguration: &apiv1.PluginConfiguration{
+							Name:       "test-plugin",
+							Parameters: map[string]string{"param": "value"},
+						},
+					},
+				},
+			},
+		}
+		plugin, err := common.NewPluginFromExternalCluster(cluster, "origin", "test-plugin")
+		Expect(err).ToNot(HaveOccurred())
+
+		validationErr := BuildErrorForParameter(plugin, "param", "invalid")
+		Expect(validationErr.GetPathComponents()).To(
+			Equal([]string{"spec", "externalClusters", "0", "plugin", "parameters", "param"}))
+		Expect(validationErr.GetValue()).To(Equal("value"))
+	})
+})
+
+var _ = Describe("Field error conversion", func() {
+	It("should convert apimachinery errors", func() {
+		list := field.ErrorList{
+			field.Invalid(field.NewPath("spec", "instances"), 0, "must be positive"),
+			field.Required(field.NewPath("spec", "storage", "size"), ""),
+			field.Invalid(field.NewPath("spec").Child("imageName"), "bad:image", "invalid image"),
+		}
+
+		errs := FromFieldErrorList(list)
+		Expect(errs).To(HaveLen(3))
+		Expect(errs[0].GetPathComponents()).To(Equal([]string{"spec", "instances"}))
+		Expect(errs[0].GetValue()).To(Equal("0"))
+		Expect(errs[0].GetMessage()).To(Equal("must be positive"))
+		Expect(errs[1].GetValue()).To(BeEmpty())
+		Expect(errs[1].GetMessage()).To(Equal(field.ErrorTypeRequired.String()))
+		Expect(errs[2].GetValue()).To(Equal("bad:image"))
+
+		Expect(NewValidationResult().AddFieldErrors(list).Errors()).To(HaveLen(3))
+	})
+
+	It("should convert to apimachinery errors", func() {
+		list := ToFieldErrorList([]*operator.ValidationError{
+			{
+				PathComponents: []string{"spec", "plugins", "0", "param"},
+				Value:          "value",
+				Message:        "invalid",
+			},
+		})
+
+		Expect(list).To(HaveLen(1))
+		Expect(list[0].Type).To(Equal(field.ErrorTypeInvalid))
+		Expect(list[0].Field).To(Equal("spec.plugins[0].param"))
+		Expect(list[0].BadValue).To(Equal("value"))
+		Expect(list[0].Detail).To(Equal("invalid"))
+	})
+})
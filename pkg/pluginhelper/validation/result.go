@@ -0,0 +1,179 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+
+	"github.com/cloudnative-pg/cnpg-i/pkg/operator"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+)
+
+// ValidationResult accumulates the validation errors and warnings
+// found while validating a Cluster, and renders them into the operator
+// response types.
+//
+// The CNPG-I protocol has no room for warnings: they are collected to
+// let the plugin log them or report them in its own way.
+type ValidationResult struct { //nolint:revive
+	errors   []*operator.ValidationError
+	warnings []*operator.ValidationError
+}
+
+// NewValidationResult creates an empty ValidationResult.
+func NewValidationResult() *ValidationResult {
+	return &ValidationResult{}
+}
+
+// AddError adds a validation error for the passed path.
+func (r *ValidationResult) AddError(pathComponents []string, value, message string) *ValidationResult {
+	r.errors = append(r.errors, &operator.ValidationError{
+		PathComponents: slices.Clone(pathComponents),
+		Value:          value,
+		Message:        message,
+	})
+
+	return r
+}
+
+// AddPathError adds a validation error for the passed Path, i.e.
+//
+//	result.AddPathError(SpecPath().Child("storage", "size"), size, "too small")
+func (r *ValidationResult) AddPathError(path *Path, value, message string) *ValidationResult {
+	return r.AddErrors(path.NewError(value, message))
+}
+
+// AddParameterError adds a validation error for a plugin parameter.
+func (r *ValidationResult) AddParameterError(plugin *common.Plugin, name, message string) *ValidationResult {
+	return r.AddErrors(BuildErrorForParameter(plugin, name, message))
+}
+
+// AddErrors adds already built validation errors. Nil errors are ignored.
+func (r *ValidationResult) AddErrors(errs ...*operator.ValidationError) *ValidationResult {
+	for _, err := range errs {
+		if err != nil {
+			r.errors = append(r.errors, err)
+		}
+	}
+
+	return r
+}
+
+// AddFieldErrors adds a list of apimachinery validation errors.
+func (r *ValidationResult) AddFieldErrors(list field.ErrorList) *ValidationResult {
+	return r.AddErrors(FromFieldErrorList(list)...)
+}
+
+// AddWarning adds a validation warning for the passed path.
+func (r *ValidationResult) AddWarning(pathComponents []string, value, message string) *ValidationResult {
+	r.warnings = append(r.warnings, &operator.ValidationError{
+		PathComponents: slices.Clone(pathComponents),
+		Value:          value,
+		Message:        message,
+	})
+
+	return r
+}
+
+// AddPathWarning adds a validation warning for the passed Path.
+func (r *ValidationResult) AddPathWarning(path *Path, value, message string) *ValidationResult {
+	r.warnings = append(r.warnings, path.NewError(value, message))
+
+	return r
+}
+
+// Merge adds the errors and the warnings of another result to this one.
+func (r *ValidationResult) Merge(other *ValidationResult) *ValidationResult {
+	if other == nil {
+		return r
+	}
+
+	r.errors = append(r.errors, other.errors...)
+	r.warnings = append(r.warnings, other.warnings...)
+
+	return r
+}
+
+// HasErrors returns true if at least one error has been added.
+func (r *ValidationResult) HasErrors() bool {
+	return len(r.errors) > 0
+}
+
+// Errors returns the deduplicated validation errors, sorted by path,
+// message and value.
+func (r *ValidationResult) Errors() []*operator.ValidationError {
+	return normalize(r.errors)
+}
+
+// Warnings returns the deduplicated validation warnings, sorted by path,
+// message and value.
+func (r *ValidationResult) Warnings() []*operator.ValidationError {
+	return normalize(r.warnings)
+}
+
+// ClusterCreateResult renders the errors as a ValidateClusterCreate result.
+func (r *ValidationResult) ClusterCreateResult() *operator.OperatorValidateClusterCreateResult {
+	return &operator.OperatorValidateClusterCreateResult{
+		ValidationErrors: r.Errors(),
+	}
+}
+
+// ClusterChangeResult renders the errors as a ValidateClusterChange result.
+func (r *ValidationResult) ClusterChangeResult() *operator.OperatorValidateClusterChangeResult {
+	return &operator.OperatorValidateClusterChangeResult{
+		ValidationErrors: r.Errors(),
+	}
+}
+
+func normalize(errs []*operator.ValidationError) []*operator.ValidationError {
+	result := slices.Clone(errs)
+	slices.SortStableFunc(result, compareValidationErrors)
+
+	return slices.CompactFunc(result, func(a, b *operator.ValidationError) bool {
+		return compareValidationErrors(a, b) == 0
+	})
+}
+
+func compareValidationErrors(a, b *operator.ValidationError) int {
+	if result := slices.CompareFunc(a.GetPathComponents(), b.GetPathComponents(), comparePathComponent); result != 0 {
+		return result
+	}
+
+	return cmp.Or(
+		cmp.Compare(a.GetMessage(), b.GetMessage()),
+		cmp.Compare(a.GetValue(), b.GetValue()),
+	)
+}
+
+// comparePathComponent compares two path components, using the numeric
+// order for list indexes.
+func comparePathComponent(a, b string) int {
+	aIndex, aErr := strconv.Atoi(a)
+	bIndex, bErr := strconv.Atoi(b)
+	if aErr == nil && bErr == nil {
+		return cmp.Compare(aIndex, bIndex)
+	}
+
+	return cmp.Compare(a, b)
+}
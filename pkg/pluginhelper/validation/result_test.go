@@ -0,0 +1,102 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidationResult", func() {
+	It("should be empty when nothing was added", func() {
+		result := NewValidationResult()
+		Expect(result.HasErrors()).To(BeFalse())
+		Expect(result.Errors()).To(BeEmpty())
+		Expect(result.ClusterCreateResult().GetValidationErrors()).To(BeEmpty())
+	})
+
+	It("should sort and deduplicate the errors", func() {
+		result := NewValidationResult().
+			AddError([]string{"spec", "storage", "size"}, "1", "too small").
+			AddError([]string{"spec", "plugins", "10", "name"}, "b", "wrong").
+			AddError([]string{"spec", "plugins", "2", "name"}, "a", "wrong").
+			AddError([]string{"spec", "storage", "size"}, "1", "too small").
+			AddErrors(nil)
+
+		Expect(result.HasErrors()).To(BeTrue())
+		errs := result.ClusterChangeResult().GetValidationErrors()
+		Expect(errs).To(HaveLen(3))
+		Expect(errs[0].GetPathComponents()).To(Equal([]string{"spec", "plugins", "2", "name"}))
+		Expect(errs[1].GetPathComponents()).To(Equal([]string{"spec", "plugins", "10", "name"}))
+		Expect(errs[2].GetPathComponents()).To(Equal([]string{"spec", "storage", "size"}))
+	})
+
+	It("should keep warnings separated from errors", func() {
+		result := NewValidationResult().
+			AddWarning([]string{"spec", "instances"}, "1", "consider using more instances")
+
+		Expect(result.HasErrors()).To(BeFalse())
+		Expect(result.Warnings()).To(HaveLen(1))
+		Expect(result.ClusterCreateResult().GetValidationErrors()).To(BeEmpty())
+	})
+
+	It("should add errors and warnings for typed paths", func() {
+		result := NewValidationResult().
+			AddPathError(SpecPath().Child("postgresql", "parameters").Key("shared_buffers"), "1", "too small").
+			AddPathWarning(SpecPath().Child("instances"), "1", "consider using more instances")
+
+		Expect(result.Errors()).To(HaveLen(1))
+		Expect(result.Errors()[0].GetPathComponents()).To(
+			Equal([]string{"spec", "postgresql", "parameters", "shared_buffers"}))
+		Expect(result.Errors()[0].GetValue()).To(Equal("1"))
+		Expect(result.Errors()[0].GetMessage()).To(Equal("too small"))
+
+		Expect(result.Warnings()).To(HaveLen(1))
+		Expect(result.Warnings()[0].GetPathComponents()).To(Equal([]string{"spec", "instances"}))
+	})
+
+	It("should add plugin parameter errors and merge other results", func() {
+		plugin := common.NewPlugin(apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				Plugins: []apiv1.PluginConfiguration{
+					{
+						Name:       "test-plugin",
+						Parameters: map[string]string{"param": "value"},
+					},
+				},
+			},
+		}, "test-plugin")
+
+		other := NewValidationResult().
+			AddWarning([]string{"spec"}, "", "warning")
+		result := NewValidationResult().
+			AddParameterError(plugin, "param", "invalid").
+			Merge(other).
+			Merge(nil)
+
+		Expect(result.Errors()).To(HaveLen(1))
+		Expect(result.Errors()[0].GetValue()).To(Equal("value"))
+		Expect(result.Warnings()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,44 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/cloudnative-pg/cnpg-i/pkg/operator"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+)
+
+// ValidateParameterTemplates checks that every templated parameter of
+// the plugin can be rendered against its Cluster. It is meant to be used
+// while implementing ValidateClusterCreate and ValidateClusterChange.
+func ValidateParameterTemplates(plugin *common.Plugin) []*operator.ValidationError {
+	templateErrors := plugin.ValidateParameterTemplates()
+
+	names := slices.Sorted(maps.Keys(templateErrors))
+	result := make([]*operator.ValidationError, 0, len(names))
+	for _, name := range names {
+		result = append(result, BuildErrorForParameter(plugin, name, templateErrors[name].Error()))
+	}
+
+	return result
+}
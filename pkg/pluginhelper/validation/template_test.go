@@ -0,0 +1,77 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateParameterTemplates", func() {
+	newPlugin := func(parameters map[string]string) *common.Plugin {
+		cluster := apiv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cluster-example",
+				Namespace: "default",
+			},
+			Spec: apiv1.ClusterSpec{
+				ImageName: "ghcr.io/cloudnative-pg/postgresql:17.2",
+				Plugins: []apiv1.PluginConfiguration{
+					{Name: "other-plugin"},
+					{Name: "test-plugin", Parameters: parameters},
+				},
+			},
+		}
+
+		return common.NewPlugin(cluster, "test-plugin")
+	}
+
+	It("should accept valid templates", func() {
+		plugin := newPlugin(map[string]string{
+			"path":  "/backups/{{ .Namespace }}/{{ .Name }}",
+			"plain": "value",
+		})
+		Expect(ValidateParameterTemplates(plugin)).To(BeEmpty())
+	})
+
+	It("should report the broken templates sorted by parameter name", func() {
+		plugin := newPlugin(map[string]string{
+			"path":    "{{ .Labels.missing }}",
+			"plain":   "value",
+			"archive": "{{ .Name ",
+		})
+
+		errs := ValidateParameterTemplates(plugin)
+		Expect(errs).To(HaveLen(2))
+
+		Expect(errs[0].GetPathComponents()).To(Equal([]string{"spec", "plugins", "1", "archive"}))
+		Expect(errs[0].GetValue()).To(Equal("{{ .Name "))
+		Expect(errs[0].GetMessage()).ToNot(BeEmpty())
+
+		Expect(errs[1].GetPathComponents()).To(Equal([]string{"spec", "plugins", "1", "path"}))
+		Expect(errs[1].GetValue()).To(Equal("{{ .Labels.missing }}"))
+		Expect(errs[1].GetMessage()).ToNot(BeEmpty())
+	})
+})
@@ -0,0 +1,221 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+var (
+	// gucNameRegex matches the name of a PostgreSQL configuration parameter,
+	// including the ones defined by extensions (i.e. `pg_stat_statements.max`)
+	gucNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)?$`)
+
+	// cronParser parses schedules in the same format used by the
+	// ScheduledBackup resource, which includes the seconds field
+	cronParser = cron.NewParser(
+		cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+	)
+
+	// azureBlobHostSuffixes are the suffixes of the Azure Blob Storage
+	// endpoints in the public and sovereign clouds
+	azureBlobHostSuffixes = []string{
+		".blob.core.windows.net",
+		".blob.core.chinacloudapi.cn",
+		".blob.core.usgovcloudapi.net",
+	}
+)
+
+// IsS3URL checks that the value is an S3 URL, i.e. `s3://bucket/path`.
+func IsS3URL(value string) error {
+	return isBucketURL(value, "s3", "must be a valid S3 URL, i.e. s3://bucket/path")
+}
+
+// IsGCSURL checks that the value is a Google Cloud Storage URL,
+// i.e. `gs://bucket/path`.
+func IsGCSURL(value string) error {
+	return isBucketURL(value, "gs", "must be a valid Google Cloud Storage URL, i.e. gs://bucket/path")
+}
+
+// IsAzureURL checks that the value is an Azure Blob Storage URL,
+// i.e. `https://account.blob.core.windows.net/container/path`.
+// Emulators, such as Azurite, are accepted when addressed by host
+// and port, i.e. `http://azurite:10000/account/container/path`.
+func IsAzureURL(value string) error {
+	const message = "must be a valid Azure Blob Storage URL, i.e. https://account.blob.core.windows.net/container/path"
+
+	parsedURL, err := url.Parse(value)
+	if err != nil || parsedURL.Hostname() == "" {
+		return errors.New(message)
+	}
+
+	pathSegments := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	isAzureHost := slices.ContainsFunc(azureBlobHostSuffixes, func(suffix string) bool {
+		return strings.HasSuffix(parsedURL.Hostname(), suffix)
+	})
+
+	switch {
+	case isAzureHost:
+		// https://account.blob.core.windows.net/container
+		if parsedURL.Scheme == "https" && pathSegments[0] != "" {
+			return nil
+		}
+
+	case parsedURL.Port() != "":
+		// http://host:port/account/container
+		if (parsedURL.Scheme == "https" || parsedURL.Scheme == "http") && len(pathSegments) >= 2 {
+			return nil
+		}
+	}
+
+	return errors.New(message)
+}
+
+// IsObjectStoreURL checks that the value is an S3, Google Cloud Storage
+// or Azure Blob Storage URL.
+func IsObjectStoreURL(value string) error {
+	if IsS3URL(value) == nil || IsGCSURL(value) == nil || IsAzureURL(value) == nil {
+		return nil
+	}
+
+	return errors.New("must be a valid S3 (s3://), Google Cloud Storage (gs://) " +
+		"or Azure Blob Storage (https://account.blob.core.windows.net/container) URL")
+}
+
+func isBucketURL(value, scheme, message string) error {
+	parsedURL, err := url.Parse(value)
+	if err != nil || parsedURL.Scheme != scheme || parsedURL.Host == "" {
+		return errors.New(message)
+	}
+
+	return nil
+}
+
+// IsCronSchedule checks that the value is a schedule in the format used
+// by the ScheduledBackup resource: six fields, starting with the seconds,
+// or a descriptor like `@daily`.
+func IsCronSchedule(value string) error {
+	if _, err := cronParser.Parse(value); err != nil {
+		return fmt.Errorf("must be a valid schedule with seconds, i.e. \"0 0 0 * * *\": %w", err)
+	}
+
+	return nil
+}
+
+// IsDNS1123Label checks that the value is a valid DNS-1123 label, as
+// the name of most of the Kubernetes resources.
+func IsDNS1123Label(value string) error {
+	if errs := k8svalidation.IsDNS1123Label(value); len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// IsDNS1123Subdomain checks that the value is a valid DNS-1123 subdomain.
+func IsDNS1123Subdomain(value string) error {
+	if errs := k8svalidation.IsDNS1123Subdomain(value); len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// IsGUCName checks that the value is a valid PostgreSQL configuration
+// parameter name.
+func IsGUCName(value string) error {
+	if !gucNameRegex.MatchString(value) {
+		return errors.New("must be a valid PostgreSQL configuration parameter name, i.e. shared_buffers")
+	}
+
+	return nil
+}
+
+// IsQuantity checks that the value is a Kubernetes quantity, i.e. `10Gi`.
+func IsQuantity(value string) error {
+	if _, err := resource.ParseQuantity(value); err != nil {
+		return errors.New("must be a valid quantity, i.e. 512Mi or 10Gi")
+	}
+
+	return nil
+}
+
+// IsDuration checks that the value is a duration, i.e. `1h30m`.
+func IsDuration(value string) error {
+	if _, err := time.ParseDuration(value); err != nil {
+		return errors.New("must be a valid duration, i.e. 30s or 1h30m")
+	}
+
+	return nil
+}
+
+// IsBool checks that the value is a boolean, i.e. `true` or `false`.
+func IsBool(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return errors.New("must be a boolean, i.e. true or false")
+	}
+
+	return nil
+}
+
+// IsInteger checks that the value is an integer.
+func IsInteger(value string) error {
+	if _, err := strconv.Atoi(value); err != nil {
+		return errors.New("must be an integer")
+	}
+
+	return nil
+}
+
+// IsIntegerInRange creates a validator checking that the value is an
+// integer between minValue and maxValue, both included.
+func IsIntegerInRange(minValue, maxValue int) ParameterValidator {
+	return func(value string) error {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < minValue || number > maxValue {
+			return fmt.Errorf("must be an integer between %d and %d", minValue, maxValue)
+		}
+
+		return nil
+	}
+}
+
+// IsOneOf creates a validator checking that the value is one of
+// the allowed ones.
+func IsOneOf(allowedValues ...string) ParameterValidator {
+	return func(value string) error {
+		if !slices.Contains(allowedValues, value) {
+			return fmt.Errorf("must be one of: %s", strings.Join(allowedValues, ", "))
+		}
+
+		return nil
+	}
+}
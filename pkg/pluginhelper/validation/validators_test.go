@@ -0,0 +1,123 @@
+/*
+Copyright © contributors to CloudNativePG, established as
+CloudNativePG a Series of LF Projects, LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	apiv1 "github.com/cloudnative-pg/api/pkg/api/v1"
+
+	"github.com/cloudnative-pg/cnpg-i-machinery/pkg/pluginhelper/common"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parameter validators", func() {
+	DescribeTable(
+		"checking values",
+		func(validator ParameterValidator, value string, valid bool) {
+			err := validator(value)
+			if valid {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("S3 URL", IsS3URL, "s3://bucket/path", true),
+		Entry("S3 URL without path", IsS3URL, "s3://bucket", true),
+		Entry("S3 URL with wrong scheme", IsS3URL, "gs://bucket/path", false),
+		Entry("S3 URL without bucket", IsS3URL, "s3:///path", false),
+		Entry("GCS URL", IsGCSURL, "gs://bucket/path", true),
+		Entry("GCS URL with wrong scheme", IsGCSURL, "s3://bucket/path", false),
+		Entry("Azure URL", IsAzureURL, "https://account.blob.core.windows.net/container/path", true),
+		Entry("Azure emulator URL", IsAzureURL, "http://azurite:10000/account/container", true),
+		Entry("Azure URL without container", IsAzureURL, "https://account.blob.core.windows.net/", false),
+		Entry("Azure URL with plain http", IsAzureURL, "http://account.blob.core.windows.net/container", false),
+		Entry("Azure emulator URL without container", IsAzureURL, "http://azurite:10000/account", false),
+		Entry("plain https URL as Azure URL", IsAzureURL, "https://example.com/foo", false),
+		Entry("plain https URL as object store URL", IsObjectStoreURL, "https://example.com/foo", false),
+		Entry("Object store URL", IsObjectStoreURL, "gs://bucket", true),
+		Entry("Object store URL with unknown scheme", IsObjectStoreURL, "ftp://bucket", false),
+		Entry("cron schedule", IsCronSchedule, "0 0 0 * * *", true),
+		Entry("cron descriptor", IsCronSchedule, "@daily", true),
+		Entry("cron schedule without seconds", IsCronSchedule, "0 0 * * *", false),
+		Entry("DNS-1123 label", IsDNS1123Label, "cluster-example", true),
+		Entry("DNS-1123 label with dots", IsDNS1123Label, "cluster.example", false),
+		Entry("DNS-1123 subdomain", IsDNS1123Subdomain, "cluster.example", true),
+		Entry("DNS-1123 subdomain with uppercase letters", IsDNS1123Subdomain, "Cluster", false),
+		Entry("GUC name", IsGUCName, "shared_buffers", true),
+		Entry("extension GUC name", IsGUCName, "pg_stat_statements.max", true),
+		Entry("GUC name with spaces", IsGUCName, "shared buffers", false),
+		Entry("quantity", IsQuantity, "10Gi", true),
+		Entry("wrong quantity", IsQuantity, "10GB", false),
+		Entry("duration", IsDuration, "1h30m", true),
+		Entry("wrong duration", IsDuration, "1 day", false),
+		Entry("boolean", IsBool, "true", true),
+		Entry("wrong boolean", IsBool, "yes", false),
+		Entry("integer", IsInteger, "42", true),
+		Entry("wrong integer", IsInteger, "4.2", false),
+		Entry("integer in range", IsIntegerInRange(1, 19), "19", true),
+		Entry("integer out of range", IsIntegerInRange(1, 19), "20", false),
+		Entry("allowed value", IsOneOf("gzip", "zstd"), "zstd", true),
+		Entry("not allowed value", IsOneOf("gzip", "zstd"), "lz4", false),
+	)
+})
+
+var _ = Describe("ValidateParameter", func() {
+	var plugin *common.Plugin
+
+	BeforeEach(func() {
+		plugin = common.NewPlugin(apiv1.Cluster{
+			Spec: apiv1.ClusterSpec{
+				Plugins: []apiv1.PluginConfiguration{
+					{
+						Name: "test-plugin",
+						Parameters: map[string]string{
+							"destinationPath": "s3://bucket/path",
+							"schedule":        "every day",
+						},
+					},
+				},
+			},
+		}, "test-plugin")
+	})
+
+	It("should accept valid parameters", func() {
+		Expect(ValidateParameter(plugin, "destinationPath", IsObjectStoreURL)).To(BeNil())
+		Expect(ValidateRequiredParameter(plugin, "destinationPath", IsS3URL)).To(BeNil())
+	})
+
+	It("should build an error for invalid parameters", func() {
+		err := ValidateParameter(plugin, "schedule", IsCronSchedule)
+		Expect(err).ToNot(BeNil())
+		Expect(err.GetPathComponents()).To(Equal([]string{"spec", "plugins", "0", "schedule"}))
+		Expect(err.GetValue()).To(Equal("every day"))
+		Expect(err.GetMessage()).To(ContainSubstring("must be a valid schedule"))
+	})
+
+	It("should skip optional parameters that are not set", func() {
+		Expect(ValidateParameter(plugin, "missing", IsDuration)).To(BeNil())
+	})
+
+	It("should report required parameters that are not set", func() {
+		err := ValidateRequiredParameter(plugin, "missing", IsDuration)
+		Expect(err).ToNot(BeNil())
+		Expect(err.GetMessage()).To(Equal("required parameter"))
+	})
+})